@@ -9,17 +9,19 @@ import (
 	"github.com/spf13/cobra"
 )
 
-// bindCmd represents the bind command
-var bindCmd = &cobra.Command{
-	Use:                "bind",
-	Short:              bind.ShortDesc,
-	Long:               bind.LongDesc,
-	DisableFlagParsing: true,
-	RunE:               bind.Run,
-}
-
-func init() {
-	rootCmd.AddCommand(bindCmd)
+// newBindCmd returns the bind command, which writes to env and looks up its variables
+func newBindCmd(env *bind.Env) *cobra.Command {
+	bindCmd := &cobra.Command{
+		Use:                "bind",
+		Short:              bind.ShortDesc,
+		Long:               bind.LongDesc,
+		DisableFlagParsing: true,
+		SilenceErrors:      true,
+		SilenceUsage:       true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return bind.Run(cmd, args, env)
+		},
+	}
 
 	// Here you will define your flags and configuration settings.
 
@@ -30,4 +32,5 @@ func init() {
 	// Cobra supports local flags which will only run when this command
 	// is called directly, e.g.:
 	// bindCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
+	return bindCmd
 }
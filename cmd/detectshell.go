@@ -0,0 +1,50 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"fmt"
+	"text/tabwriter"
+
+	"github.com/vipcxj/argonaut/internal/bind"
+
+	"github.com/spf13/cobra"
+)
+
+// newDetectShellCmd returns the command printing the shell decided by 'bind --shell-type=auto' from env
+func newDetectShellCmd(env *bind.Env) *cobra.Command {
+	return &cobra.Command{
+		Use:   "detect-shell",
+		Short: "Print the shell type decided by 'bind --shell-type=auto' and how it was found",
+		Long: `Detect-shell prints the shell type 'bind --shell-type=auto' would use, where it was found
+and the parent process chain walked, for debugging the detection.
+
+The detection uses $` + bind.ShellEnvName + ` when set, else the first known shell in the parent process chain,
+matched by its exact name, which is not started by a wrapper such as make, else $SHELL or $COMSPEC.
+The wrappers are listed comma-separated in $` + bind.ShellWrappersEnvName + `.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			detection, err := bind.DetectShell(env)
+			if err != nil {
+				return err
+			}
+			out := cmd.OutOrStdout()
+			fmt.Fprintf(out, "shell type: %s\n", detection.Shell.Type)
+			fmt.Fprintf(out, "shell: %s\n", detection.Shell.Name)
+			if detection.Shell.Path != "" {
+				fmt.Fprintf(out, "path: %s\n", detection.Shell.Path)
+			}
+			fmt.Fprintf(out, "source: %s\n", detection.Source)
+			if len(detection.Chain) > 0 {
+				fmt.Fprintln(out, "process chain:")
+				w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+				for _, p := range detection.Chain {
+					fmt.Fprintf(w, "  %d\t%s\t%s\t%s\n", p.Pid, p.Name, p.Exe, p.Note)
+				}
+				w.Flush()
+			}
+			return nil
+		},
+	}
+}
@@ -0,0 +1,113 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/vipcxj/argonaut/internal/bind"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+var allowedLintFormats = []string{"text", "json"}
+
+// newLintFlags returns the flags of lint, parsed into a new set on every run since flag parsing
+// is disabled to keep the bind flags after '--' untouched.
+func newLintFlags() *pflag.FlagSet {
+	fs := pflag.NewFlagSet("lint", pflag.ContinueOnError)
+	fs.StringP("format", "", allowedLintFormats[0], fmt.Sprintf("The output format, allowed values: %s", strings.Join(allowedLintFormats, ", ")))
+	return fs
+}
+
+// newLintCmd returns the command checking a bind spec without user arguments, with the variables of env
+func newLintCmd(env *bind.Env) *cobra.Command {
+	lintCmd := &cobra.Command{
+		Use:   "lint [flags] -- [bind flags]",
+		Short: "Check the flag specifications of a bind command without user arguments",
+		Long: `Lint checks the bind flags given after '--', e.g. the arguments of bind in a script without
+the '-- $0 "$@"' part, and reports errors such as short names colliding with -h or with each other,
+empty values outside the choices, empty args ranges and everything bind itself rejects, and warnings
+for options without effect and suspicious variable names. It exits with 1 when an error is found.`,
+		DisableFlagParsing: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			dash := slices.Index(args, "--")
+			lintArgs, bindArgs := args, []string{}
+			if dash >= 0 {
+				lintArgs, bindArgs = args[:dash], args[dash+1:]
+			}
+			fs := newLintFlags()
+			fs.SetOutput(io.Discard)
+			fs.BoolP("help", "h", false, "")
+			if err := fs.Parse(lintArgs); err != nil {
+				return err
+			}
+			if help, _ := fs.GetBool("help"); help {
+				return cmd.Help()
+			}
+			if dash < 0 || fs.NArg() > 0 {
+				return fmt.Errorf("the bind flags must be given after '--'")
+			}
+			format, err := fs.GetString("format")
+			if err != nil {
+				return err
+			}
+			if !slices.Contains(allowedLintFormats, format) {
+				return fmt.Errorf("invalid format: %s, allowed formats are: %v", format, allowedLintFormats)
+			}
+			cmd.SilenceUsage = true
+			issues := bind.LintSpec(bindArgs, env)
+			errors, warnings := 0, 0
+			for _, issue := range issues {
+				if issue.Severity == bind.LintSeverityError {
+					errors++
+				} else {
+					warnings++
+				}
+			}
+			out := cmd.OutOrStdout()
+			if format == "json" {
+				if issues == nil {
+					issues = []bind.LintIssue{}
+				}
+				data, err := json.MarshalIndent(struct {
+					Issues   []bind.LintIssue `json:"issues"`
+					Errors   int              `json:"errors"`
+					Warnings int              `json:"warnings"`
+				}{issues, errors, warnings}, "", "  ")
+				if err != nil {
+					return err
+				}
+				fmt.Fprintln(out, string(data))
+			} else {
+				for _, issue := range issues {
+					var b strings.Builder
+					fmt.Fprintf(&b, "%s: ", issue.Severity)
+					if issue.Flag != "" {
+						fmt.Fprintf(&b, "flag %s: ", issue.Flag)
+					}
+					fmt.Fprintf(&b, "%s (%s)", issue.Message, issue.Code)
+					fmt.Fprintln(out, b.String())
+				}
+				if len(issues) == 0 {
+					fmt.Fprintln(out, "no issues found")
+				} else {
+					fmt.Fprintf(out, "%d error(s), %d warning(s)\n", errors, warnings)
+				}
+			}
+			if errors > 0 {
+				cmd.SilenceErrors = true
+				return fmt.Errorf("lint found %d error(s)", errors)
+			}
+			return nil
+		},
+	}
+	lintCmd.Flags().AddFlagSet(newLintFlags())
+	return lintCmd
+}
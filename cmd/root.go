@@ -5,15 +5,21 @@ package cmd
 
 import (
 	"fmt"
+	"io"
+	"os"
+
+	"github.com/vipcxj/argonaut/internal/bind"
 
 	"github.com/spf13/cobra"
 )
 
-// rootCmd represents the base command when called without any subcommands
-var rootCmd = &cobra.Command{
-	Use:   "argonaut",
-	Short: "Interactive arg helper: select, validate and export CLI args to shell",
-	Long: `Argonaut is an interactive command-line helper for declarative argument resolution.
+// newRootCmd returns the base command with all child commands, writing to env and looking up its variables.
+// Every run builds a new tree, so runs with their own env do not share any state.
+func newRootCmd(env *bind.Env) *cobra.Command {
+	rootCmd := &cobra.Command{
+		Use:   "argonaut",
+		Short: "Interactive arg helper: select, validate and export CLI args to shell",
+		Long: `Argonaut is an interactive command-line helper for declarative argument resolution.
 It accepts argument specifications (defaults, allowed values, required/multi flags)
 and supports two run modes: interactive and not interactive.
 
@@ -26,24 +32,20 @@ Argonaut can:
 Designed to be embedded in scripts and pipelines, Argonaut provides a compact,
 scriptable UX for gathering and validating parameters while preserving
 automation-friendly behavior.`,
-	// Uncomment the following line if your bare application
-	// has an action associated with it:
-	Run: func(cmd *cobra.Command, args []string) {
-		fmt.Print("argonaut")
-	},
-}
-
-// Execute adds all child commands to the root command and sets flags appropriately.
-// This is called by main.main(). It only needs to happen once to the rootCmd.
-func Execute() int {
-	err := rootCmd.Execute()
-	if err != nil {
-		return 1
+		// Uncomment the following line if your bare application
+		// has an action associated with it:
+		Run: func(cmd *cobra.Command, args []string) {
+			fmt.Fprint(cmd.OutOrStdout(), "argonaut")
+		},
 	}
-	return 0
-}
+	rootCmd.SetOut(env.Stdout)
+	rootCmd.SetErr(env.Stderr)
+	rootCmd.AddCommand(newBindCmd(env))
+	rootCmd.AddCommand(newDetectShellCmd(env))
+	rootCmd.AddCommand(newLintCmd(env))
+	rootCmd.AddCommand(newSpecCmd(env))
+	rootCmd.AddCommand(newUnpersistCmd(env))
 
-func init() {
 	// Here you will define your flags and configuration settings.
 	// Cobra supports persistent flags, which, if defined here,
 	// will be global for your application.
@@ -52,4 +54,32 @@ func init() {
 
 	// Cobra also supports local flags, which will only run
 	// when this action is called directly.
+	return rootCmd
+}
+
+// Execute runs argonaut with the arguments, the output streams and the environment variables of the process.
+// This is called by main.main().
+func Execute() int {
+	return Run(os.Args[1:], os.Stdout, os.Stderr, os.LookupEnv)
+}
+
+// Run runs argonaut with args, without the program name, writing to stdout and stderr and looking up the
+// environment variables by lookupEnv instead of the process, so several runs may run concurrently.
+// It returns the exit code.
+func Run(args []string, stdout io.Writer, stderr io.Writer, lookupEnv func(key string) (string, bool)) int {
+	rootCmd := newRootCmd(&bind.Env{Stdout: stdout, Stderr: stderr, LookupEnv: lookupEnv})
+	// cobra falls back to os.Args for nil args
+	rootCmd.SetArgs(append([]string{}, args...))
+	// cobra prints the usage on errors to the out stream, which is stdout here, so it is printed below
+	rootCmd.SilenceUsage = true
+	cmd, err := rootCmd.ExecuteC()
+	if err != nil {
+		// like cobra, the usage follows the errors of a command which was found, the root command silences
+		// the usage only for cobra
+		if _, _, findErr := rootCmd.Find(args); findErr == nil && (cmd == rootCmd || !cmd.SilenceUsage) {
+			fmt.Fprintln(stderr, cmd.UsageString())
+		}
+		return 1
+	}
+	return 0
 }
@@ -0,0 +1,136 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/vipcxj/argonaut/internal/bind"
+	"github.com/vipcxj/argonaut/spec"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// newSpecExportFlags returns the flags of spec export, parsed into a new set on every run since flag parsing
+// is disabled to keep the bind flags after '--' untouched.
+func newSpecExportFlags() *pflag.FlagSet {
+	fs := pflag.NewFlagSet("export", pflag.ContinueOnError)
+	fs.StringP("format", "", spec.Formats[0], fmt.Sprintf("The output format, allowed values: %s", strings.Join(spec.Formats, ", ")))
+	return fs
+}
+
+// newSpecCmd returns the command grouping the commands converting between bind flags and spec files
+func newSpecCmd(env *bind.Env) *cobra.Command {
+	specCmd := &cobra.Command{
+		Use:   "spec",
+		Short: "Convert between the flags of a bind command and a spec file",
+		Long: `A spec file holds the flags of a bind command as yaml or json, so a script can use
+'argonaut bind --spec=<file> -- $0 "$@"' instead of a long list of flags.`,
+	}
+	specCmd.AddCommand(newSpecExportCmd(env))
+	specCmd.AddCommand(newSpecRenderCmd())
+	specCmd.AddCommand(newSpecSchemaCmd())
+	return specCmd
+}
+
+// newSpecExportCmd returns the command writing the bind flags as a spec file, checked with the variables of env
+func newSpecExportCmd(env *bind.Env) *cobra.Command {
+	specExportCmd := &cobra.Command{
+		Use:   "export [flags] -- [bind flags]",
+		Short: "Write the flags of a bind command as a spec file",
+		Long: `Export checks the bind flags given after '--' like bind does and writes the options which are
+given as a yaml or json spec file to stdout, rendering it back gives the same flags.`,
+		DisableFlagParsing: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			dash := slices.Index(args, "--")
+			exportArgs, bindArgs := args, []string{}
+			if dash >= 0 {
+				exportArgs, bindArgs = args[:dash], args[dash+1:]
+			}
+			fs := newSpecExportFlags()
+			fs.SetOutput(io.Discard)
+			fs.BoolP("help", "h", false, "")
+			if err := fs.Parse(exportArgs); err != nil {
+				return err
+			}
+			if help, _ := fs.GetBool("help"); help {
+				return cmd.Help()
+			}
+			if dash < 0 || fs.NArg() > 0 {
+				return fmt.Errorf("the bind flags must be given after '--'")
+			}
+			format, err := fs.GetString("format")
+			if err != nil {
+				return err
+			}
+			if !slices.Contains(spec.Formats, format) {
+				return fmt.Errorf("invalid format: %s, allowed formats are: %v", format, spec.Formats)
+			}
+			cmd.SilenceUsage = true
+			f, err := bind.ExportSpec(bindArgs, env)
+			if err != nil {
+				return err
+			}
+			data, err := spec.Marshal(f, format)
+			if err != nil {
+				return err
+			}
+			_, err = cmd.OutOrStdout().Write(data)
+			return err
+		},
+	}
+	specExportCmd.Flags().AddFlagSet(newSpecExportFlags())
+	return specExportCmd
+}
+
+// newSpecRenderCmd returns the command printing the bind flags of a spec file
+func newSpecRenderCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "render <spec file>",
+		Short: "Print the flags of a bind command from a spec file",
+		Long: `Render reads a yaml or json spec file (json if the extension is .json) and prints the equivalent
+bind flags quoted for sh, one per line, to paste into a script.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+			f, err := bind.LoadSpecFile(args[0])
+			if err != nil {
+				return err
+			}
+			out, err := bind.RenderSpecShell(f)
+			if err != nil {
+				return err
+			}
+			if out != "" {
+				fmt.Fprintln(cmd.OutOrStdout(), out)
+			}
+			return nil
+		},
+	}
+}
+
+// newSpecSchemaCmd returns the command printing the JSON Schema of spec files
+func newSpecSchemaCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "schema",
+		Short: "Print the JSON Schema of spec files",
+		Long: `Schema prints the JSON Schema of spec files for editors, e.g. with the yaml language server add
+'# yaml-language-server: $schema=<path of the schema>' to the top of a yaml spec file. Spec files are
+checked against the same schema when they are loaded.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+			data, err := spec.Schema()
+			if err != nil {
+				return err
+			}
+			_, err = cmd.OutOrStdout().Write(data)
+			return err
+		},
+	}
+}
@@ -0,0 +1,44 @@
+/*
+Copyright © 2025 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/vipcxj/argonaut/internal/bind"
+
+	"github.com/spf13/cobra"
+)
+
+// newUnpersistCmd returns the command removing the block written by 'bind --sh-persist', the files are
+// found by the variables of env
+func newUnpersistCmd(env *bind.Env) *cobra.Command {
+	unpersistCmd := &cobra.Command{
+		Use:   "unpersist",
+		Short: "Remove the variables persisted by 'bind --sh-persist' for a command",
+		Long: `Unpersist removes the block managed by argonaut for the command from the file
+written by 'bind --sh-persist'. The --sh-persist and --name values must match the ones given to bind,
+the name defaults to the first user argument of bind, usually $0.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			target, err := cmd.Flags().GetString("sh-persist")
+			if err != nil {
+				return err
+			}
+			if target == bind.AllowedShPersists[0] {
+				return fmt.Errorf("--sh-persist is required, allowed targets are: %v", bind.AllowedShPersists[1:])
+			}
+			name, err := cmd.Flags().GetString("name")
+			if err != nil {
+				return err
+			}
+			return bind.UnpersistShVars(target, name, env)
+		},
+	}
+	unpersistCmd.Flags().StringP("sh-persist", "", bind.AllowedShPersists[0], fmt.Sprintf("The persist target given to bind, allowed values: %s", strings.Join(bind.AllowedShPersists[1:], ", ")))
+	unpersistCmd.Flags().StringP("name", "n", "", "The name of the command given to bind")
+	unpersistCmd.MarkFlagRequired("name")
+	return unpersistCmd
+}
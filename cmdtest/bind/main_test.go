@@ -12,6 +12,6 @@ func TestCLI(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
-	ts.Register("argonaut", cmd.Execute)
+	ts.Register("argonaut", cmd.Run)
 	ts.Run(t, false)
 }
@@ -38,11 +38,14 @@ type TestGroup struct {
 // TestSuite 测试套件
 type TestSuite struct {
 	groups   []*TestGroup
-	commands map[string]func() int
+	commands map[string]RunFunc
 	backings map[*TestGroup]*groupBacking
 	mu       sync.Mutex
 }
 
+// RunFunc 执行命令，args 不含命令名，输出写入 stdout/stderr，环境变量通过 lookupEnv 读取，返回 exit code
+type RunFunc func(args []string, stdout io.Writer, stderr io.Writer, lookupEnv func(key string) (string, bool)) int
+
 type groupBacking struct {
 	path      string
 	root      *yaml.Node
@@ -54,7 +57,7 @@ type groupBacking struct {
 func Read(dir string) (*TestSuite, error) {
 	suite := &TestSuite{
 		groups:   make([]*TestGroup, 0),
-		commands: make(map[string]func() int),
+		commands: make(map[string]RunFunc),
 		backings: make(map[*TestGroup]*groupBacking),
 	}
 
@@ -129,16 +132,13 @@ func Read(dir string) (*TestSuite, error) {
 
 // Register 注册命令行实现
 // cmd: 对应 YAML 中的 cmd 字段
-// run: 执行逻辑，返回 exit code
-func (s *TestSuite) Register(cmd string, run func() int) {
+// run: 执行逻辑，返回 exit code；不能修改进程的全局状态，用例会并行执行
+func (s *TestSuite) Register(cmd string, run RunFunc) {
 	s.commands[cmd] = run
 }
 
-// Run 执行测试；update=true 时自动写回期望
+// Run 并行执行测试；update=true 时自动写回期望
 func (s *TestSuite) Run(t *testing.T, update bool) {
-	s.mu.Lock()
-	defer s.mu.Unlock()
-
 	for _, group := range s.groups {
 		g := group
 		t.Run(g.Name, func(t *testing.T) {
@@ -150,6 +150,7 @@ func (s *TestSuite) Run(t *testing.T, update bool) {
 				}
 				idx := i
 				t.Run(name, func(t *testing.T) {
+					t.Parallel()
 					s.runSingleTest(t, g, idx, update)
 				})
 			}
@@ -165,80 +166,31 @@ func (s *TestSuite) runSingleTest(t *testing.T, group *TestGroup, idx int, updat
 		t.Fatalf("Command '%s' not registered", test.Cmd)
 	}
 
-	// 保存现场
-	oldArgs := os.Args
-	oldStdout := os.Stdout
-	oldStderr := os.Stderr
-	type envSnapshot struct {
-		value  string
-		exists bool
-	}
-	oldEnv := make(map[string]envSnapshot)
-	for k := range test.Env {
-		val, exists := os.LookupEnv(k)
-		oldEnv[k] = envSnapshot{value: val, exists: exists}
-	}
-
-	// 设置现场
-	args := append([]string{test.Cmd}, test.Args...)
-	os.Args = args
-	for k, v := range test.Env {
-		os.Setenv(k, v)
+	// 用例的环境变量覆盖进程的环境变量
+	lookupEnv := func(key string) (string, bool) {
+		if val, ok := test.Env[key]; ok {
+			return val, true
+		}
+		return os.LookupEnv(key)
 	}
 
-	rOut, wOut, _ := os.Pipe()
-	rErr, wErr, _ := os.Pipe()
-	os.Stdout = wOut
-	os.Stderr = wErr
-
+	var stdout, stderr bytes.Buffer
 	var exitCode int
-	done := make(chan struct{}, 2)
-	var gotStdout, gotStderr string
-
-	go func() {
-		var buf bytes.Buffer
-		_, _ = io.Copy(&buf, rOut)
-		gotStdout = buf.String()
-		done <- struct{}{}
-	}()
-	go func() {
-		var buf bytes.Buffer
-		_, _ = io.Copy(&buf, rErr)
-		gotStderr = buf.String()
-		done <- struct{}{}
-	}()
-
 	func() {
 		defer func() {
 			if r := recover(); r != nil {
-                stack := debug.Stack()
-                t.Errorf("panic: %v\n%s", r, stack)
+				stack := debug.Stack()
+				t.Errorf("panic: %v\n%s", r, stack)
 				exitCode = -1
 			}
 		}()
-		exitCode = runFunc()
+		exitCode = runFunc(append([]string{}, test.Args...), &stdout, &stderr, lookupEnv)
 	}()
+	gotStdout, gotStderr := stdout.String(), stderr.String()
 
-	// 恢复现场
-	_ = wOut.Close()
-	_ = wErr.Close()
-	<-done
-	<-done
-	_ = rOut.Close()
-	_ = rErr.Close()
-
-	os.Stdout = oldStdout
-	os.Stderr = oldStderr
-	os.Args = oldArgs
-	for k, snap := range oldEnv {
-		if snap.exists {
-			os.Setenv(k, snap.value)
-		} else {
-			os.Unsetenv(k)
-		}
-	}
-
-	// 比较或更新
+	// 比较或更新；同一文件的用例共享 yaml 节点，写回时需要加锁
+	s.mu.Lock()
+	defer s.mu.Unlock()
 	changes := s.applyExpect(t, group, idx, gotStdout, gotStderr, exitCode, update)
 	if update && len(changes) > 0 {
 		if err := s.persistGroup(group); err != nil {
@@ -0,0 +1,31 @@
+package bind
+
+import (
+	"fmt"
+	"strings"
+)
+
+// buildCshLiteral renders s as a csh/tcsh word. The value is single-quoted, but csh still performs
+// history substitution on '!' inside single quotes, so '!' and the single quote itself are closed
+// out of the quotes and escaped with a backslash. A line break inside quotes must be preceded by
+// a backslash, which csh removes while keeping the line break.
+func buildCshLiteral(s string) string {
+	replacer := strings.NewReplacer(`'`, `'\''`, `!`, `'\!'`, "\n", "\\\n")
+	return "'" + replacer.Replace(s) + "'"
+}
+
+// exportEnvVarCshLike renders setenv for the env scope and set for the shell scope,
+// csh has no readonly variables and tcsh's set -r is not portable, so readonly is ignored.
+func exportEnvVarCshLike(varName string, val string, scope Scope) string {
+	if scope == ScopeShell {
+		return fmt.Sprintf("set %s = %s", varName, buildCshLiteral(val))
+	}
+	return fmt.Sprintf("setenv %s %s", varName, buildCshLiteral(val))
+}
+
+func unsetEnvVarCshLike(varName string, scope Scope) string {
+	if scope == ScopeShell {
+		return fmt.Sprintf("unset %s", varName)
+	}
+	return fmt.Sprintf("unsetenv %s", varName)
+}
@@ -0,0 +1,36 @@
+package bind
+
+import "testing"
+
+func TestBuildCshLiteral(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"empty", "", "''"},
+		{"plain", `a "b" $c`, `'a "b" $c'`},
+		{"single_quote", "it's", `'it'\''s'`},
+		{"history", "hi!!", `'hi'\!''\!''`},
+		{"newline", "a\nb", "'a\\\nb'"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := buildCshLiteral(tc.in); got != tc.want {
+				t.Fatalf("got %q want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestExportEnvVarCshLike(t *testing.T) {
+	if got := exportEnvVarCshLike("NAME", "v", ScopeShell); got != "set NAME = 'v'" {
+		t.Fatalf("got %q", got)
+	}
+	if got := exportEnvVarCshLike("NAME", "v", ScopeEnv); got != "setenv NAME 'v'" {
+		t.Fatalf("got %q", got)
+	}
+	if got := unsetEnvVarCshLike("NAME", ScopeEnv); got != "unsetenv NAME" {
+		t.Fatalf("got %q", got)
+	}
+}
@@ -0,0 +1,195 @@
+package bind
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/shirou/gopsutil/v4/process"
+)
+
+// ShellEnvName is the environment variable overriding the detection of --shell-type=auto,
+// it holds a shell type or the name of a shell program, e.g. "powershell" or "pwsh".
+const ShellEnvName = "ARGONAUT_SHELL"
+
+// ShellWrappersEnvName is the environment variable holding a comma-separated list of the
+// wrapper processes replacing DefaultShellWrappers.
+const ShellWrappersEnvName = "ARGONAUT_SHELL_WRAPPERS"
+
+// DefaultShellWrappers are the programs running their commands through a shell of their own,
+// e.g. the /bin/sh of a make recipe. A shell started by a wrapper is skipped by the detection,
+// so the shell the wrapper was started from is found instead.
+var DefaultShellWrappers = []string{"make", "gmake", "bmake", "just", "npm", "npx", "yarn", "pnpm"}
+
+// knownShells maps the exact names of shell programs to their shell types.
+// fish has no backend of its own and keeps the sh syntax.
+var knownShells = map[string]ShellType{
+	"sh":         ShellTypeSh,
+	"bash":       ShellTypeSh,
+	"zsh":        ShellTypeSh,
+	"ksh":        ShellTypeSh,
+	"mksh":       ShellTypeSh,
+	"dash":       ShellTypeSh,
+	"ash":        ShellTypeSh,
+	"yash":       ShellTypeSh,
+	"fish":       ShellTypeSh,
+	"powershell": ShellTypePowershell,
+	"pwsh":       ShellTypePowershell,
+	"cmd":        ShellTypeCmd,
+	"nu":         ShellTypeNushell,
+	"elvish":     ShellTypeElvish,
+	"xonsh":      ShellTypeXonsh,
+	"csh":        ShellTypeCsh,
+	"tcsh":       ShellTypeCsh,
+}
+
+// ShellProcess is a process of the parent chain walked by the detection.
+type ShellProcess struct {
+	Pid  int32
+	Name string
+	Exe  string
+	// Note tells how the detection treated the process: "selected", "wrapper", "skipped, started by wrapper <name>" or empty
+	Note string
+}
+
+// ShellDetection is the outcome of the detection, Source is where the shell was found:
+// ARGONAUT_SHELL, the parent process chain, SHELL or COMSPEC.
+type ShellDetection struct {
+	Shell  ShellInfo
+	Source string
+	Chain  []ShellProcess
+}
+
+// normalizeShellName reduces a process name or path to the bare program name,
+// e.g. "/bin/Bash", "-bash" (a login shell) and "bash.exe" become "bash".
+func normalizeShellName(name string) string {
+	if name == "" {
+		return ""
+	}
+	name = strings.ToLower(filepath.Base(name))
+	name = strings.TrimSuffix(name, ".exe")
+	return strings.TrimPrefix(name, "-")
+}
+
+// shellTypeOfName returns the shell type of a shell program name, matched exactly after normalization.
+func shellTypeOfName(name string) (ShellType, bool) {
+	shellType, ok := knownShells[normalizeShellName(name)]
+	return shellType, ok
+}
+
+// shellWrappers returns the wrapper list from ARGONAUT_SHELL_WRAPPERS, or the default list when it is unset.
+func shellWrappers(env *Env) []string {
+	value, ok := env.LookupEnv(ShellWrappersEnvName)
+	if !ok {
+		return DefaultShellWrappers
+	}
+	var wrappers []string
+	for _, w := range strings.Split(value, ",") {
+		if w = normalizeShellName(strings.TrimSpace(w)); w != "" {
+			wrappers = append(wrappers, w)
+		}
+	}
+	return wrappers
+}
+
+// processName returns the normalized name of the process, falling back to its executable.
+func (p ShellProcess) processName() string {
+	if p.Name != "" {
+		return normalizeShellName(p.Name)
+	}
+	return normalizeShellName(p.Exe)
+}
+
+// selectShellProcess returns the index of the first known shell in the chain, ordered from the
+// parent upwards, which is not started by a wrapper, or -1. The notes of the chain are filled in.
+func selectShellProcess(chain []ShellProcess, wrappers []string) int {
+	for i := range chain {
+		name := chain[i].processName()
+		if checkInStringSlice(name, wrappers) {
+			chain[i].Note = "wrapper"
+			continue
+		}
+		if _, ok := knownShells[name]; !ok {
+			continue
+		}
+		if i+1 < len(chain) {
+			if parent := chain[i+1].processName(); checkInStringSlice(parent, wrappers) {
+				chain[i].Note = fmt.Sprintf("skipped, started by wrapper %s", parent)
+				continue
+			}
+		}
+		chain[i].Note = "selected"
+		return i
+	}
+	return -1
+}
+
+// parentProcessChain returns the parent process of argonaut and its ancestors.
+func parentProcessChain() ([]ShellProcess, error) {
+	p, err := process.NewProcess(int32(os.Getppid()))
+	if err != nil {
+		return nil, fmt.Errorf("cannot get parent process: %w", err)
+	}
+	var chain []ShellProcess
+	seen := map[int32]struct{}{}
+	for p != nil {
+		if _, ok := seen[p.Pid]; ok {
+			break
+		}
+		seen[p.Pid] = struct{}{}
+		name, _ := p.Name()
+		exe, _ := p.Exe()
+		chain = append(chain, ShellProcess{Pid: p.Pid, Name: name, Exe: exe})
+		parent, err := p.Parent()
+		if err != nil || parent == nil {
+			break
+		}
+		p = parent
+	}
+	return chain, nil
+}
+
+// DetectShell decides the shell for --shell-type=auto: ARGONAUT_SHELL when set, else the first
+// known shell in the parent process chain which is not started by a wrapper, else the default
+// shell in SHELL or COMSPEC. Unknown default shells are treated as sh. The variables are looked up in env.
+func DetectShell(env *Env) (*ShellDetection, error) {
+	if value := env.getenv(ShellEnvName); value != "" && value != ShellTypeAuto.String() {
+		if shellType, err := ShellTypeString(value); err == nil {
+			return &ShellDetection{Shell: ShellInfo{Type: shellType, Name: value}, Source: ShellEnvName}, nil
+		}
+		if shellType, ok := shellTypeOfName(value); ok {
+			return &ShellDetection{Shell: ShellInfo{Type: shellType, Name: value}, Source: ShellEnvName}, nil
+		}
+		return nil, fmt.Errorf("invalid %s: %s, allowed values are the shell types %v or a shell program name", ShellEnvName, value, ShellTypeStrings()[1:])
+	}
+
+	detection := &ShellDetection{}
+	chain, err := parentProcessChain()
+	if err == nil {
+		detection.Chain = chain
+		if i := selectShellProcess(chain, shellWrappers(env)); i >= 0 {
+			shellType, _ := shellTypeOfName(chain[i].processName())
+			detection.Shell = ShellInfo{Type: shellType, Name: chain[i].Name, Path: chain[i].Exe}
+			detection.Source = "parent process"
+			return detection, nil
+		}
+	}
+
+	// SHELL and COMSPEC only hold the default shell of the user, not the one actually running
+	for _, envName := range []string{"SHELL", "COMSPEC"} {
+		if path := env.getenv(envName); path != "" {
+			shellType, ok := shellTypeOfName(path)
+			if !ok {
+				shellType = ShellTypeSh
+			}
+			detection.Shell = ShellInfo{Type: shellType, Name: filepath.Base(path), Path: path}
+			detection.Source = envName
+			return detection, nil
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("user shell not detected: %w", err)
+	}
+	return nil, fmt.Errorf("user shell not detected")
+}
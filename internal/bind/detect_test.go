@@ -0,0 +1,93 @@
+package bind
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestShellTypeOfName(t *testing.T) {
+	tests := []struct {
+		name string
+		want ShellType
+		ok   bool
+	}{
+		{"bash", ShellTypeSh, true},
+		{"-zsh", ShellTypeSh, true},
+		{"/usr/bin/tcsh", ShellTypeCsh, true},
+		{"/opt/microsoft/powershell/7/pwsh.exe", ShellTypePowershell, true},
+		{"CMD.EXE", ShellTypeCmd, true},
+		{"nu", ShellTypeNushell, true},
+		{"ssh", ShellTypeAuto, false},
+		{"bashbug", ShellTypeAuto, false},
+		{"fish_indent", ShellTypeAuto, false},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, ok := shellTypeOfName(tc.name)
+			if ok != tc.ok || ok && got != tc.want {
+				t.Fatalf("got %v, %v want %v, %v", got, ok, tc.want, tc.ok)
+			}
+		})
+	}
+}
+
+func TestSelectShellProcess(t *testing.T) {
+	chain := []ShellProcess{
+		{Pid: 5, Name: "ssh"},
+		{Pid: 4, Name: "sh"},
+		{Pid: 3, Name: "make"},
+		{Pid: 2, Name: "zsh"},
+		{Pid: 1, Name: "bash"},
+	}
+	if got := selectShellProcess(chain, DefaultShellWrappers); got != 3 {
+		t.Fatalf("got %d want 3", got)
+	}
+	var notes []string
+	for _, p := range chain {
+		notes = append(notes, p.Note)
+	}
+	want := []string{"", "skipped, started by wrapper make", "wrapper", "selected", ""}
+	if !reflect.DeepEqual(notes, want) {
+		t.Fatalf("got %q want %q", notes, want)
+	}
+	if got := selectShellProcess([]ShellProcess{{Pid: 1, Name: "ssh"}}, nil); got != -1 {
+		t.Fatalf("got %d want -1", got)
+	}
+}
+
+func TestShellWrappers(t *testing.T) {
+	env := testEnv(map[string]string{ShellWrappersEnvName: "make, Task.exe,"})
+	if got, want := shellWrappers(env), []string{"make", "task"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %q want %q", got, want)
+	}
+}
+
+func TestDetectShellEnv(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    ShellType
+		wantErr bool
+	}{
+		{"xonsh", ShellTypeXonsh, false},
+		{"powershell", ShellTypePowershell, false},
+		{"tcsh", ShellTypeCsh, false},
+		{"ssh", ShellTypeAuto, true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.value, func(t *testing.T) {
+			detection, err := DetectShell(testEnv(map[string]string{ShellEnvName: tc.value}))
+			if tc.wantErr {
+				if err == nil {
+					t.Fatalf("expected error for %q", tc.value)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if detection.Shell.Type != tc.want || detection.Source != ShellEnvName {
+				t.Fatalf("got %v from %s want %v", detection.Shell.Type, detection.Source, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,22 @@
+package bind
+
+import (
+	"fmt"
+	"strings"
+)
+
+// buildElvishLiteral renders s as an elvish single-quoted string, where a single quote is
+// written twice and everything else, line breaks included, is literal.
+func buildElvishLiteral(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+// exportEnvVarElvish renders a set-env call, variables declared by the eval'ed output do not
+// survive it, so the shell and env scopes both set the environment.
+func exportEnvVarElvish(varName string, val string) string {
+	return fmt.Sprintf("set-env %s %s", varName, buildElvishLiteral(val))
+}
+
+func unsetEnvVarElvish(varName string) string {
+	return fmt.Sprintf("unset-env %s", varName)
+}
@@ -0,0 +1,23 @@
+package bind
+
+import "testing"
+
+func TestBuildElvishLiteral(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"empty", "", "''"},
+		{"plain", `a "b" $c \d`, `'a "b" $c \d'`},
+		{"newline", "a\nb", "'a\nb'"},
+		{"single_quote", "it's ''", "'it''s '''''"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := buildElvishLiteral(tc.in); got != tc.want {
+				t.Fatalf("got %q want %q", got, tc.want)
+			}
+		})
+	}
+}
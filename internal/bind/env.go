@@ -0,0 +1,50 @@
+package bind
+
+import (
+	"errors"
+	"io"
+	"os"
+	"runtime"
+)
+
+// Env is what a bind run takes from its process: the output streams and the environment variables.
+// Runs with their own Env touch no globals of the process, so several of them may run concurrently.
+type Env struct {
+	// Stdout receives the output to eval
+	Stdout io.Writer
+	// Stderr receives the help, the explanation and the debug output
+	Stderr io.Writer
+	// LookupEnv looks up an environment variable like os.LookupEnv
+	LookupEnv func(key string) (string, bool)
+}
+
+// OSEnv returns the Env of the process: os.Stdout, os.Stderr and os.LookupEnv.
+func OSEnv() *Env {
+	return &Env{Stdout: os.Stdout, Stderr: os.Stderr, LookupEnv: os.LookupEnv}
+}
+
+// quiet returns an Env with the same environment variables which discards the output.
+func (e *Env) quiet() *Env {
+	return &Env{Stdout: io.Discard, Stderr: io.Discard, LookupEnv: e.LookupEnv}
+}
+
+// getenv returns the value of the environment variable, empty if it is unset.
+func (e *Env) getenv(key string) string {
+	value, _ := e.LookupEnv(key)
+	return value
+}
+
+// userHomeDir returns the home directory of the user from the environment variables like os.UserHomeDir.
+func (e *Env) userHomeDir() (string, error) {
+	envName := "HOME"
+	switch runtime.GOOS {
+	case "windows":
+		envName = "USERPROFILE"
+	case "plan9":
+		envName = "home"
+	}
+	if home := e.getenv(envName); home != "" {
+		return home, nil
+	}
+	return "", errors.New("$" + envName + " is not defined")
+}
@@ -0,0 +1,36 @@
+package bind
+
+import (
+	"io"
+	"runtime"
+	"testing"
+)
+
+// testEnv returns an Env with only the variables given, discarding the output.
+func testEnv(vars map[string]string) *Env {
+	return &Env{
+		Stdout: io.Discard,
+		Stderr: io.Discard,
+		LookupEnv: func(key string) (string, bool) {
+			value, ok := vars[key]
+			return value, ok
+		},
+	}
+}
+
+func TestEnvUserHomeDir(t *testing.T) {
+	envName := "HOME"
+	switch runtime.GOOS {
+	case "windows":
+		envName = "USERPROFILE"
+	case "plan9":
+		envName = "home"
+	}
+	home, err := testEnv(map[string]string{envName: "/home/a"}).userHomeDir()
+	if err != nil || home != "/home/a" {
+		t.Fatalf("got %q, %v want %q", home, err, "/home/a")
+	}
+	if _, err := testEnv(map[string]string{envName: ""}).userHomeDir(); err == nil {
+		t.Fatalf("expected error without $%s", envName)
+	}
+}
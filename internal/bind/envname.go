@@ -0,0 +1,258 @@
+package bind
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+var AllowedEnvNameSanitizes = []string{"error", "replace"}
+
+// envNameReplacement replaces the invalid characters of an env name and prefixes a leading digit
+// when the names are sanitized.
+const envNameReplacement = "_"
+
+func isAsciiLetter(r rune) bool {
+	return (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z')
+}
+
+func isAsciiDigit(r rune) bool {
+	return r >= '0' && r <= '9'
+}
+
+// envNameRule describes the variable names accepted by a shell type or an output target.
+type envNameRule struct {
+	// target is shown in errors, e.g. "shell type sh"
+	target string
+	// isRune reports whether r may appear in a name
+	isRune func(r rune) bool
+	// noLeadingDigit rejects names starting with a digit, they are prefixed with '_' when sanitized
+	noLeadingDigit bool
+	// caseInsensitive names collide when they only differ in case
+	caseInsensitive bool
+	// reservedPrefixes are prefixes of names which cannot be set, compared case-insensitively
+	reservedPrefixes []string
+}
+
+func isIdentifierRune(r rune) bool {
+	return isAsciiLetter(r) || isAsciiDigit(r) || r == '_'
+}
+
+// shellEnvNameRule returns the name rule of the shell type. sh and powershell only accept
+// ASCII letters, digits and '_'; cmd accepts everything but '=' and control characters,
+// the other special characters are escaped in the output. Environment variables are
+// case-insensitive on Windows.
+func shellEnvNameRule(shellType ShellType) envNameRule {
+	rule := envNameRule{
+		target: fmt.Sprintf("shell type %s", shellType),
+		isRune: isIdentifierRune,
+	}
+	switch shellType {
+	case ShellTypeCmd:
+		rule.isRune = func(r rune) bool {
+			return r != '=' && r >= 0x20 && r != 0x7f
+		}
+		rule.caseInsensitive = true
+	case ShellTypePowershell:
+		rule.caseInsensitive = true
+	default:
+		rule.noLeadingDigit = true
+	}
+	return rule
+}
+
+// CheckEnvName validates a variable name against the identifier rules of the shell type.
+func CheckEnvName(shellType ShellType, name string) error {
+	return checkEnvName(shellEnvNameRule(shellType), name)
+}
+
+func checkEnvName(rule envNameRule, name string) error {
+	if name == "" {
+		return fmt.Errorf("environment variable name is empty")
+	}
+	for _, r := range name {
+		if !rule.isRune(r) {
+			return fmt.Errorf("invalid environment variable name %q for %s: character %q is not allowed", name, rule.target, r)
+		}
+	}
+	if rule.noLeadingDigit && isAsciiDigit(rune(name[0])) {
+		return fmt.Errorf("invalid environment variable name %q for %s: it cannot start with a digit", name, rule.target)
+	}
+	for _, prefix := range rule.reservedPrefixes {
+		if strings.HasPrefix(strings.ToUpper(name), prefix) {
+			return fmt.Errorf("invalid environment variable name %q for %s: the prefix %s is reserved", name, rule.target, prefix)
+		}
+	}
+	return nil
+}
+
+// SanitizeEnvName replaces the characters not allowed by the shell type with '_',
+// and prefixes a name starting with a digit with '_' for sh, e.g. "1st.value" -> "_1st_value".
+func SanitizeEnvName(shellType ShellType, name string) string {
+	return sanitizeEnvName(shellEnvNameRule(shellType), name)
+}
+
+func sanitizeEnvName(rule envNameRule, name string) string {
+	var b strings.Builder
+	for _, r := range name {
+		if rule.isRune(r) {
+			b.WriteRune(r)
+		} else {
+			b.WriteString(envNameReplacement)
+		}
+	}
+	sanitized := b.String()
+	if rule.noLeadingDigit && sanitized != "" && isAsciiDigit(rune(sanitized[0])) {
+		sanitized = envNameReplacement + sanitized
+	}
+	return sanitized
+}
+
+// resolveEnvName validates or sanitizes a single variable name according to the sanitize mode.
+func resolveEnvName(rule envNameRule, sanitize string, name string) (string, error) {
+	switch sanitize {
+	case "error":
+		return name, checkEnvName(rule, name)
+	case "replace":
+		name = sanitizeEnvName(rule, name)
+		return name, checkEnvName(rule, name)
+	default:
+		return "", fmt.Errorf("unsupported env name sanitize mode: %s", sanitize)
+	}
+}
+
+// ResolveEnvNames computes the variable name of every flag and of the help variable for the
+// shell type, or for the output target when the output is not "shell", validates or sanitizes them,
+// and rejects flags mapping to the same variable. The variables of the keys of a map flag with
+// the "vars" map output and the help variable, when it is output with the flags, take part as well.
+// The resolved names are stored in FlagSpec.VarName and CmdSpec.HelpVar.
+func ResolveEnvNames(shellType ShellType, spec *CmdSpec, sanitize string) error {
+	if !checkInStringSlice(sanitize, AllowedEnvNameSanitizes) {
+		return fmt.Errorf("invalid env name sanitize mode: %s, allowed modes are: %v", sanitize, AllowedEnvNameSanitizes)
+	}
+	return resolveEnvNames(shellType, spec, sanitize, func(err error) error {
+		return err
+	})
+}
+
+// resolveEnvNames resolves the names like ResolveEnvNames and passes every invalid name and collision
+// to report, it stops at the first error report returns. A flag keeps its name when it is invalid.
+func resolveEnvNames(shellType ShellType, spec *CmdSpec, sanitize string, report func(err error) error) error {
+	var keys []string
+	for k := range spec.Flags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	rule := outputEnvNameRule(shellType, spec.Output)
+	owners := newEnvNameOwners(rule)
+	for _, key := range keys {
+		fs := spec.Flags[key]
+		varName, err := resolveEnvName(rule, sanitize, calcEnvName(key, fs.EnvName, spec.EnvPrefix))
+		if err != nil {
+			if err := report(fmt.Errorf("flag %s: %w", key, err)); err != nil {
+				return err
+			}
+		} else if err := owners.claim(varName, envVarOwner{flag: key}); err != nil {
+			if err := report(err); err != nil {
+				return err
+			}
+		}
+		fs.VarName = varName
+	}
+	for _, key := range keys {
+		for _, varName := range mapKeyVarNames(spec.Flags[key].VarName, spec.Flags[key]) {
+			if err := owners.claim(varName, envVarOwner{flag: key, mapKey: true}); err != nil {
+				if err := report(err); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	// the help variable is always output for the shell
+	helpVar, err := resolveEnvName(shellEnvNameRule(shellType), sanitize, spec.HelpVar)
+	if err != nil {
+		return report(fmt.Errorf("help var: %w", err))
+	}
+	if spec.Output == "shell" || spec.Output == "" {
+		if err := owners.claim(helpVar, envVarOwner{}); err != nil {
+			if err := report(err); err != nil {
+				return err
+			}
+		}
+	}
+	spec.HelpVar = helpVar
+	return nil
+}
+
+// mapKeyVarNames returns the variables of the allowed keys of a map flag with the "vars" map output
+// and the variable varName. Without allowed keys the variables are not known.
+func mapKeyVarNames(varName string, fs *FlagSpec) []string {
+	if fs.Type != FlagTypeMap || fs.MapOutput != "vars" {
+		return nil
+	}
+	var names []string
+	for _, key := range fs.MapKeys {
+		names = append(names, varName+"_"+calcMapKeyEnvSuffix(key))
+	}
+	return names
+}
+
+// EnvNameCollisionError reports two flags, a flag and a key variable of a map flag, or a flag and the help
+// variable which map to the same environment variable.
+type EnvNameCollisionError struct {
+	VarName string
+	// Flag is the flag of the second claim of the variable, or of the first one if the second is the help variable
+	Flag   string
+	owners string
+}
+
+func (e *EnvNameCollisionError) Error() string {
+	return fmt.Sprintf("%s map to the same environment variable %s", e.owners, e.VarName)
+}
+
+// envVarOwner is a flag, a key variable of a map flag when mapKey is set, or the help variable when flag is empty.
+type envVarOwner struct {
+	flag   string
+	mapKey bool
+}
+
+func (o envVarOwner) String() string {
+	switch {
+	case o.flag == "":
+		return "the help var"
+	case o.mapKey:
+		return "key variable of map flag " + o.flag
+	default:
+		return "flag " + o.flag
+	}
+}
+
+// envNameOwners records which flag or variable owns each variable name, using the case rule of the target.
+type envNameOwners struct {
+	rule   envNameRule
+	owners map[string]envVarOwner
+}
+
+func newEnvNameOwners(rule envNameRule) *envNameOwners {
+	return &envNameOwners{rule: rule, owners: make(map[string]envVarOwner)}
+}
+
+// claim records owner for the variable, or reports the previous owner of the same variable.
+func (o *envNameOwners) claim(varName string, owner envVarOwner) error {
+	key := varName
+	if o.rule.caseInsensitive {
+		key = strings.ToUpper(varName)
+	}
+	if previous, exists := o.owners[key]; exists {
+		err := &EnvNameCollisionError{VarName: varName, Flag: owner.flag, owners: previous.String() + " and " + owner.String()}
+		if owner.flag == "" {
+			err.Flag = previous.flag
+		}
+		if !previous.mapKey && !owner.mapKey && previous.flag != "" && owner.flag != "" {
+			err.owners = fmt.Sprintf("flags %s and %s", previous.flag, owner.flag)
+		}
+		return err
+	}
+	o.owners[key] = owner
+	return nil
+}
@@ -0,0 +1,109 @@
+package bind
+
+import "testing"
+
+func TestCheckEnvName(t *testing.T) {
+	cases := []struct {
+		shellType ShellType
+		name      string
+		valid     bool
+	}{
+		{ShellTypeSh, "NAME_1", true},
+		{ShellTypeSh, "_1", true},
+		{ShellTypeSh, "1ST", false},
+		{ShellTypeSh, "with-dash", false},
+		{ShellTypeSh, "a;b", false},
+		{ShellTypeSh, "", false},
+		{ShellTypeSh, "ÄPFEL", false},
+		{ShellTypePowershell, "1ST", true},
+		{ShellTypePowershell, "a b", false},
+		{ShellTypeCmd, "my var&x", true},
+		{ShellTypeCmd, "a=b", false},
+		{ShellTypeCmd, "a\nb", false},
+	}
+	for _, c := range cases {
+		err := CheckEnvName(c.shellType, c.name)
+		if (err == nil) != c.valid {
+			t.Fatalf("CheckEnvName(%s, %q): got error %v want valid %v", c.shellType, c.name, err, c.valid)
+		}
+	}
+}
+
+func TestSanitizeEnvName(t *testing.T) {
+	cases := []struct {
+		shellType ShellType
+		name      string
+		want      string
+	}{
+		{ShellTypeSh, "1ST.VALUE", "_1ST_VALUE"},
+		{ShellTypeSh, "my var", "my_var"},
+		{ShellTypeSh, "ÄPFEL", "_PFEL"},
+		{ShellTypePowershell, "1ST.VALUE", "1ST_VALUE"},
+		{ShellTypeCmd, "a=b c", "a_b c"},
+	}
+	for _, c := range cases {
+		got := SanitizeEnvName(c.shellType, c.name)
+		if got != c.want {
+			t.Fatalf("SanitizeEnvName(%s, %q): got %q want %q", c.shellType, c.name, got, c.want)
+		}
+		if err := CheckEnvName(c.shellType, got); err != nil {
+			t.Fatalf("SanitizeEnvName(%s, %q) is invalid: %v", c.shellType, c.name, err)
+		}
+	}
+}
+
+func TestResolveEnvNames(t *testing.T) {
+	spec := &CmdSpec{
+		EnvPrefix: "APP_",
+		HelpVar:   "IS_HELP",
+		Flags: map[string]*FlagSpec{
+			"1st.value": {},
+			"explicit":  {EnvName: "my-name"},
+		},
+	}
+	if err := ResolveEnvNames(ShellTypeSh, spec, "error"); err == nil {
+		t.Fatalf("expected error for invalid explicit name")
+	}
+	if err := ResolveEnvNames(ShellTypeSh, spec, "replace"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := spec.Flags["1st.value"].VarName; got != "APP_1ST_VALUE" {
+		t.Fatalf("got %q want %q", got, "APP_1ST_VALUE")
+	}
+	if got := spec.Flags["explicit"].VarName; got != "my_name" {
+		t.Fatalf("got %q want %q", got, "my_name")
+	}
+
+	spec.Flags["other"] = &FlagSpec{EnvName: "MY-NAME"}
+	if err := ResolveEnvNames(ShellTypeSh, spec, "replace"); err != nil {
+		t.Fatalf("names differing in case must not collide for sh: %v", err)
+	}
+	if err := ResolveEnvNames(ShellTypeCmd, spec, "replace"); err == nil {
+		t.Fatalf("expected case-insensitive collision for cmd")
+	}
+}
+
+func TestResolveEnvNamesMapKeysAndHelpVar(t *testing.T) {
+	spec := &CmdSpec{
+		HelpVar: "IS_HELP",
+		Flags: map[string]*FlagSpec{
+			"label":     {Type: FlagTypeMap, MapOutput: "vars", MapKeys: []string{"env", "team"}},
+			"label-env": {},
+		},
+	}
+	if err := ResolveEnvNames(ShellTypeSh, spec, "error"); err == nil {
+		t.Fatalf("expected collision with the variable of key env")
+	}
+	spec.Flags["label"].MapOutput = "json"
+	if err := ResolveEnvNames(ShellTypeSh, spec, "error"); err != nil {
+		t.Fatalf("unexpected error for map output json: %v", err)
+	}
+	spec.Flags["is-help"] = &FlagSpec{}
+	if err := ResolveEnvNames(ShellTypeSh, spec, "error"); err == nil {
+		t.Fatalf("expected collision with the help var")
+	}
+	spec.Output = "dotenv"
+	if err := ResolveEnvNames(ShellTypeSh, spec, "error"); err != nil {
+		t.Fatalf("the help var is not output with dotenv: %v", err)
+	}
+}
@@ -0,0 +1,152 @@
+package bind
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/spf13/pflag"
+)
+
+// emptyValueSentinel replaces the empty values of the flags when the user args are parsed again to record
+// the sources, argv cannot hold a NUL so no value given on the command line equals it.
+const emptyValueSentinel = "\x00"
+
+// sourceRecorder is the value of a flag when the user args are parsed again, it records whether the
+// last value of the flag was given on the command line or was its empty value.
+type sourceRecorder struct {
+	name    string
+	typ     string
+	sources map[string]ValueSource
+}
+
+func (r *sourceRecorder) String() string { return "" }
+
+func (r *sourceRecorder) Type() string { return r.typ }
+
+func (r *sourceRecorder) Set(value string) error {
+	if value == emptyValueSentinel {
+		r.sources[r.name] = ValueSourceEmptyValue
+	} else {
+		r.sources[r.name] = ValueSourceCli
+	}
+	return nil
+}
+
+// recordValueSources parses args again with copies of flags whose values record the source of the last
+// value of each given flag. The parse is the one of pflag, so grouped short flags, attached values and
+// the "--" terminator are read like in the real parse.
+func recordValueSources(flags *pflag.FlagSet, args []string) map[string]ValueSource {
+	sources := make(map[string]ValueSource)
+	recorder := pflag.NewFlagSet("sources", pflag.ContinueOnError)
+	recorder.SetOutput(io.Discard)
+	recorder.ParseErrorsAllowlist.UnknownFlags = true
+	flags.VisitAll(func(f *pflag.Flag) {
+		copied := *f
+		copied.Changed = false
+		copied.Value = &sourceRecorder{name: f.Name, typ: f.Value.Type(), sources: sources}
+		if f.NoOptDefVal != "" {
+			copied.NoOptDefVal = emptyValueSentinel
+		}
+		recorder.AddFlag(&copied)
+	})
+	// the args were parsed successfully before, so this parse cannot fail
+	recorder.Parse(args)
+	return sources
+}
+
+// valueSource returns where the value of a resolved flag came from, sources are the ones recorded by
+// recordValueSources.
+func valueSource(fs *FlagSpec, flagName string, changed bool, sources map[string]ValueSource) ValueSource {
+	switch {
+	case fs.Missing:
+		return ValueSourceMissing
+	case !changed:
+		return ValueSourceDefault
+	case fs.NoOptDefValue != "" && sources[flagName] == ValueSourceEmptyValue:
+		return ValueSourceEmptyValue
+	default:
+		return ValueSourceCli
+	}
+}
+
+// explainAssignments returns the variables of a flag with their values as they would be output,
+// the entries of an "assoc" map are shown as NAME[key].
+func explainAssignments(varName string, fs *FlagSpec) ([]envVarAssignment, error) {
+	if fs.Type != FlagTypeMap || fs.MapOutput != "assoc" {
+		return flagAssignments(varName, fs)
+	}
+	keys, grouped := groupMapValues(fs.Value)
+	var assignments []envVarAssignment
+	for _, key := range keys {
+		val, err := joinMapValues(fs, grouped[key])
+		if err != nil {
+			return nil, err
+		}
+		assignments = append(assignments, envVarAssignment{fmt.Sprintf("%s[%s]", varName, key), val})
+	}
+	return assignments, nil
+}
+
+// explainShellType describes the shell type of the output and how it was decided.
+func explainShellType(spec *CmdSpec, env *Env) (string, error) {
+	if spec.ShellType != ShellTypeAuto {
+		return fmt.Sprintf("%s (--shell-type)", spec.ShellType), nil
+	}
+	detection, err := DetectShell(env)
+	if err != nil {
+		return "", fmt.Errorf("cannot detect user shell: %w", err)
+	}
+	shell := detection.Shell.Name
+	if detection.Shell.Path != "" && detection.Shell.Path != shell {
+		shell += " " + detection.Shell.Path
+	}
+	return fmt.Sprintf("%s (detected from %s: %s)", detection.Shell.Type, detection.Source, shell), nil
+}
+
+// WriteExplain writes how the shell type was decided and a table of every flag with its variable,
+// final value and the source of the value, for --explain. Values are quoted to show whitespace.
+func WriteExplain(w io.Writer, spec *CmdSpec, env *Env) error {
+	shellType, err := explainShellType(spec, env)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "shell type: %s\n", shellType)
+	if !isShellOutput(spec.Output) {
+		fmt.Fprintf(w, "output: %s\n", spec.Output)
+	}
+
+	var keys []string
+	for k := range spec.Flags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "FLAG\tENV VAR\tVALUE\tSOURCE")
+	for _, key := range keys {
+		fs := spec.Flags[key]
+		varName := fs.VarName
+		if varName == "" {
+			varName = calcEnvName(key, fs.EnvName, spec.EnvPrefix)
+		}
+		if fs.Missing {
+			source := fs.Source.String()
+			if fs.UnsetMissing || spec.UnsetMissing {
+				source += ", unset"
+			}
+			fmt.Fprintf(tw, "%s\t%s\t\t%s\n", key, varName, source)
+			continue
+		}
+		assignments, err := explainAssignments(varName, fs)
+		if err != nil {
+			return fmt.Errorf("flag %s: %w", key, err)
+		}
+		for _, a := range assignments {
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", key, a.Name, strconv.Quote(a.Value), fs.Source)
+		}
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,78 @@
+package bind
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/spf13/pflag"
+)
+
+func TestRecordValueSources(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		// ValueSourceDefault stands for a name flag which is not given
+		want ValueSource
+	}{
+		{"long", []string{"--name"}, ValueSourceEmptyValue},
+		{"long_with_value", []string{"--name=x"}, ValueSourceCli},
+		{"long_overridden", []string{"--name", "--name=x"}, ValueSourceCli},
+		{"long_override", []string{"--name=x", "--name"}, ValueSourceEmptyValue},
+		{"short", []string{"-n"}, ValueSourceEmptyValue},
+		{"short_group", []string{"-vn"}, ValueSourceEmptyValue},
+		{"short_with_value", []string{"-n=x"}, ValueSourceCli},
+		{"short_attached_value", []string{"-onx"}, ValueSourceDefault},
+		{"short_group_attached_value", []string{"-von"}, ValueSourceDefault},
+		{"after_terminator", []string{"--", "--name"}, ValueSourceDefault},
+		{"other_flag", []string{"--names", "x"}, ValueSourceDefault},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+			flags.StringP("name", "n", "", "")
+			flags.Lookup("name").NoOptDefVal = "anonymous"
+			flags.StringP("output", "o", "", "")
+			flags.String("names", "", "")
+			flags.BoolP("verbose", "v", false, "")
+			sources := recordValueSources(flags, tc.args)
+			got, given := sources["name"]
+			if !given {
+				got = ValueSourceDefault
+			}
+			if got != tc.want {
+				t.Fatalf("got %v want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestWriteExplain(t *testing.T) {
+	spec := &CmdSpec{
+		ShellType:    ShellTypeSh,
+		UnsetMissing: true,
+		Flags: map[string]*FlagSpec{
+			"name": {MultiFormat: []string{"comma"}, Value: []string{"a b"}, Source: ValueSourceCli},
+			"tag":  {Missing: true, Source: ValueSourceMissing},
+			"label": {
+				Type:         FlagTypeMap,
+				MapOutput:    "assoc",
+				MapDuplicate: "error",
+				Value:        []string{"env=dev"},
+				Source:       ValueSourceDefault,
+			},
+		},
+	}
+	var b strings.Builder
+	if err := WriteExplain(&b, spec, testEnv(nil)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := `shell type: sh (--shell-type)
+FLAG   ENV VAR     VALUE  SOURCE
+label  LABEL[env]  "dev"  default
+name   NAME        "a b"  cli
+tag    TAG                missing, unset
+`
+	if b.String() != want {
+		t.Fatalf("got %q want %q", b.String(), want)
+	}
+}
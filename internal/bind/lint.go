@@ -0,0 +1,262 @@
+package bind
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// LintIssue is a problem of a bind spec found by LintSpec. Errors make bind fail or misbehave,
+// warnings point at options without effect or names likely to cause trouble.
+type LintIssue struct {
+	Severity string `json:"severity"`
+	Code     string `json:"code"`
+	Flag     string `json:"flag,omitempty"`
+	Message  string `json:"message"`
+}
+
+const (
+	LintSeverityError   = "error"
+	LintSeverityWarning = "warning"
+)
+
+// suspiciousEnvNames are variables of the shells and the OS which a flag should not override,
+// the lower-case ones are tied to the upper-case variables in zsh and csh or are special in zsh and fish.
+var suspiciousEnvNames = []string{
+	"PATH", "HOME", "USER", "SHELL", "PWD", "OLDPWD", "IFS", "PS1", "PS2", "PS4", "ENV", "BASH_ENV", "CDPATH",
+	"PROMPT_COMMAND", "SHLVL", "LANG", "TERM", "TMPDIR", "TEMP", "TMP", "LD_PRELOAD", "LD_LIBRARY_PATH",
+	"DYLD_INSERT_LIBRARIES", "DYLD_LIBRARY_PATH", "PATHEXT", "COMSPEC", "SYSTEMROOT", "USERPROFILE",
+	"path", "home", "term", "user", "shell", "cdpath", "status", "argv", "prompt",
+}
+
+// lintReservedPrefix is the prefix of the environment variables read by argonaut itself.
+const lintReservedPrefix = "ARGONAUT_"
+
+type linter struct {
+	issues []LintIssue
+}
+
+func (l *linter) add(severity string, code string, flag string, format string, args ...any) {
+	l.issues = append(l.issues, LintIssue{Severity: severity, Code: code, Flag: flag, Message: fmt.Sprintf(format, args...)})
+}
+
+// givenOptions returns the long options present in the bind arguments, without their values.
+func givenOptions(bindArgs []string) map[string]bool {
+	given := make(map[string]bool)
+	for _, arg := range bindArgs {
+		if arg == "--" {
+			break
+		}
+		if !strings.HasPrefix(arg, "--") {
+			continue
+		}
+		name, _, _ := strings.Cut(arg[2:], "=")
+		given[name] = true
+	}
+	return given
+}
+
+// LintSpec validates the bind arguments of a spec without user arguments and returns every issue found.
+// bind stops at the first problem of a spec, which is reported as an "invalid-spec" error. The other
+// checks run on the options parsed without checking them, so they also report the problems of a spec
+// bind rejects: short names, variables claimed twice ("env-name-collision", including the key variables
+// of map flags and the help variable), options without effect and suspicious names.
+// The environment variables are looked up in env, the shell type auto is decided by them.
+func LintSpec(bindArgs []string, env *Env) []LintIssue {
+	l := &linter{}
+	accepted, err := collectSpecs(&cobra.Command{Use: "argonaut"}, bindArgs, []string{"lint"}, env.quiet())
+	if err == nil && accepted == nil {
+		// only the help was requested
+		return nil
+	}
+	var collision *EnvNameCollisionError
+	var short *ShortNameError
+	if err != nil && !errors.As(err, &collision) && !errors.As(err, &short) {
+		// the short names and the collisions are reported by the checks below
+		l.add(LintSeverityError, "invalid-spec", "", "%v", err)
+	}
+	spec, collisions, err := collectLintSpec(bindArgs, env)
+	if err != nil {
+		// the options cannot be read at all, bind reported why
+		return l.issues
+	}
+	given := givenOptions(bindArgs)
+	lintCmdOptions(l, spec, given)
+
+	var keys []string
+	for k := range spec.Flags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	shorts := make(map[string]string)
+	for _, key := range keys {
+		fs := spec.Flags[key]
+		lintShortName(l, key, fs, shorts)
+		lintFlagOptions(l, spec, key, fs, given)
+		lintSuspiciousName(l, key, fs.VarName)
+	}
+	for _, collision := range collisions {
+		l.add(LintSeverityError, "env-name-collision", collision.Flag, "%v", collision)
+	}
+	return l.issues
+}
+
+// collectLintSpec parses the bind options like bind without checking them: unknown options are skipped,
+// an invalid value keeps the default of its option, and only the options used by the checks of lint are read.
+// The variable names are resolved as well, every collision of them is returned.
+func collectLintSpec(bindArgs []string, env *Env) (*CmdSpec, []*EnvNameCollisionError, error) {
+	bindArgs, err := ExpandSpecArgs(bindArgs)
+	if err != nil {
+		return nil, nil, err
+	}
+	flagsName, err := collectFlagsName(bindArgs)
+	if err != nil {
+		return nil, nil, err
+	}
+	spec := &CmdSpec{Flags: make(map[string]*FlagSpec)}
+	if err := collectFlagsMulti(spec.Flags, flagsName, bindArgs); err != nil {
+		return nil, nil, err
+	}
+	flags := pflag.NewFlagSet("lint", pflag.ContinueOnError)
+	flags.ParseErrorsAllowlist.UnknownFlags = true
+	flags.SetOutput(io.Discard)
+	addBindFlags(flags, spec.Flags)
+	// pflag stops at an invalid value, the options before it are kept
+	flags.Parse(bindArgs)
+	getString := func(name string) string {
+		value, _ := flags.GetString(name)
+		return value
+	}
+	spec.EnvPrefix = getString("env-prefix")
+	spec.HelpVar = getString("help-var")
+	spec.Output = getString("output")
+	spec.ShellType, _ = ShellTypeString(getString("shell-type"))
+	if argsRange, err := NewIntRange(getString("args-range"), true); err == nil {
+		spec.ArgsRange = argsRange
+	} else {
+		spec.ArgsRange, _ = NewIntRange("", true)
+	}
+	for _, flagName := range flagsName {
+		fs := spec.Flags[flagName]
+		option := func(name string) string {
+			return fmt.Sprintf("flag-%s-%s", flagName, name)
+		}
+		fs.ShortName = getString(option("short"))
+		fs.EnvName = getString(option("env-name"))
+		fs.MapOutput = getString(option("map-output"))
+		fs.MapKeys, _ = flags.GetStringSlice(option("map-keys"))
+		fs.NoOptDefValue = getString(option("empty-value"))
+		fs.Required, _ = flags.GetBool(option("required"))
+		fs.MultiPreserve, _ = flags.GetBool(option("multi-preserve"))
+		if choices, err := flags.GetStringArray(option("choices")); err == nil {
+			fs.Choices, _ = ParseMultiValues(fs.MultiFormat, fs.MultiPreserve, choices, flagName)
+		}
+		if flags.Changed(option("default")) {
+			// only whether a default is given is checked
+			fs.Default = []string{}
+		}
+	}
+	sanitize := getString("env-name-sanitize")
+	if !checkInStringSlice(sanitize, AllowedEnvNameSanitizes) {
+		sanitize = AllowedEnvNameSanitizes[0]
+	}
+	return spec, lintEnvNames(spec, sanitize, env), nil
+}
+
+// lintEnvNames resolves the variable names of spec and returns every collision, the names bind
+// rejects as invalid are left to bind.
+func lintEnvNames(spec *CmdSpec, sanitize string, env *Env) []*EnvNameCollisionError {
+	shellType := spec.ShellType
+	if shellType == ShellTypeAuto {
+		decided, err := decideShellType(shellType, env)
+		if err != nil {
+			return nil
+		}
+		shellType = decided
+	}
+	var collisions []*EnvNameCollisionError
+	resolveEnvNames(shellType, spec, sanitize, func(err error) error {
+		var collision *EnvNameCollisionError
+		if errors.As(err, &collision) {
+			collisions = append(collisions, collision)
+		}
+		return nil
+	})
+	return collisions
+}
+
+func lintCmdOptions(l *linter, spec *CmdSpec, given map[string]bool) {
+	if !spec.ArgsRange.IsNotEmpty() {
+		l.add(LintSeverityError, "args-range-empty", "", "args range %s contains no argument count, bind always fails", spec.ArgsRange.String())
+	}
+	if spec.ShellType != ShellTypeAuto {
+		for _, option := range []string{"sh-declare", "sh-persist"} {
+			if given[option] && spec.ShellType != ShellTypeSh {
+				l.add(LintSeverityWarning, "unused-option", "", "--%s has no effect for shell type %s", option, spec.ShellType)
+			}
+		}
+		for _, option := range []string{"cmd-script", "cmd-delayed-expansion"} {
+			if given[option] && spec.ShellType != ShellTypeCmd {
+				l.add(LintSeverityWarning, "unused-option", "", "--%s has no effect for shell type %s", option, spec.ShellType)
+			}
+		}
+	}
+	lintSuspiciousName(l, "", spec.HelpVar)
+}
+
+// lintShortName checks the short name is a single character which neither collides with
+// the -h of the help flag nor with another flag, both make bind misbehave or panic.
+func lintShortName(l *linter, key string, fs *FlagSpec, shorts map[string]string) {
+	if err := checkShortName(fs.ShortName, key, shorts); err != nil {
+		l.add(LintSeverityError, err.Code, key, "%s", err.message)
+	}
+}
+
+func lintFlagOptions(l *linter, spec *CmdSpec, key string, fs *FlagSpec, given map[string]bool) {
+	unused := func(option string, reason string) {
+		if given[fmt.Sprintf("flag-%s-%s", key, option)] {
+			l.add(LintSeverityWarning, "unused-option", key, "--flag-%s-%s has no effect %s", key, option, reason)
+		}
+	}
+	if !fs.Multi && fs.Type != FlagTypeMap {
+		for _, option := range []string{"multi-format", "multi-preserve"} {
+			unused(option, "without --flag-"+key+"-multi")
+		}
+	}
+	if fs.Type != FlagTypeMap {
+		for _, option := range []string{"map-duplicate", "map-keys", "map-output"} {
+			unused(option, "for type "+fs.Type.String())
+		}
+	}
+	if !isPathFlagType(fs.Type) {
+		for _, option := range []string{"path-checks", "path-normalize"} {
+			unused(option, "for type "+fs.Type.String())
+		}
+	}
+	if !isShellOutput(spec.Output) {
+		for _, option := range []string{"scope", "export", "readonly"} {
+			unused(option, "for output "+spec.Output)
+		}
+	} else if spec.ShellType != ShellTypeAuto && spec.ShellType != ShellTypeSh {
+		unused("readonly", "for shell type "+spec.ShellType.String())
+	}
+	if fs.Required && fs.Default != nil {
+		l.add(LintSeverityWarning, "required-with-default", key, "the flag is required but has a default, which is used when it is omitted")
+	}
+	if fs.NoOptDefValue != "" && len(fs.Choices) > 0 && !checkInStringSlice(fs.NoOptDefValue, fs.Choices) {
+		l.add(LintSeverityError, "empty-value-not-in-choices", key, "empty value %s is not in allowed choices %v, so giving the flag without a value always fails", fs.NoOptDefValue, fs.Choices)
+	}
+}
+
+func lintSuspiciousName(l *linter, key string, varName string) {
+	if checkInStringSlice(varName, suspiciousEnvNames) {
+		l.add(LintSeverityWarning, "suspicious-env-name", key, "variable %s overrides a variable of the shell or the system", varName)
+	} else if strings.HasPrefix(strings.ToUpper(varName), lintReservedPrefix) {
+		l.add(LintSeverityWarning, "suspicious-env-name", key, "variable %s uses the prefix %s of the variables read by argonaut", varName, lintReservedPrefix)
+	}
+}
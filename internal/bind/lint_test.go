@@ -0,0 +1,43 @@
+package bind
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestLintSpec(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		want []string
+	}{
+		{"clean", []string{"--flag=name", "--flag-name-choices=a,b", "--flag-name-empty-value=a"}, nil},
+		{"invalid_spec", []string{"--flag=name", "--flag-name-default=c", "--flag-name-choices=a,b"}, []string{"invalid-spec"}},
+		{"short_help", []string{"--flag=name", "--flag-name-short=h"}, []string{"short-help"}},
+		{"short_duplicate", []string{"--flag=a", "--flag-a-short=x", "--flag=b", "--flag-b-short=x"}, []string{"short-duplicate"}},
+		{"short_invalid", []string{"--flag=name", "--flag-name-short=nm"}, []string{"short-invalid"}},
+		{"empty_value", []string{"--flag=name", "--flag-name-choices=a,b", "--flag-name-empty-value=c"}, []string{"empty-value-not-in-choices"}},
+		{"args_range", []string{"--args-range=(3,4)"}, []string{"args-range-empty"}},
+		{"unused_multi", []string{"--flag=name", "--flag-name-multi-format=json", "--flag-name-map-keys=a"}, []string{"unused-option", "unused-option"}},
+		{"multi_policy_without_multi", []string{"--flag=name", "--flag-name-sort=asc"}, []string{"invalid-spec"}},
+		{"issues_of_rejected_spec", []string{"--flag=a", "--flag=b", "--flag-b-env-name=A", "--flag-b-short=h", "--flag-b-map-keys=k", "--flag-c-default=1"}, []string{"invalid-spec", "short-help", "unused-option", "env-name-collision"}},
+		{"unused_shell_option", []string{"--shell-type=cmd", "--sh-declare=local", "--flag=name", "--flag-name-readonly"}, []string{"unused-option", "unused-option"}},
+		{"unused_scope", []string{"--output=dotenv", "--flag=name", "--flag-name-scope=env"}, []string{"unused-option"}},
+		{"required_with_default", []string{"--flag=name", "--flag-name-required", "--flag-name-default=a"}, []string{"required-with-default"}},
+		{"help_var_collision", []string{"--flag=is-help"}, []string{"env-name-collision"}},
+		{"map_key_collision", []string{"--flag=label", "--flag-label-type=map", "--flag-label-map-output=vars", "--flag-label-map-keys=env", "--flag=label-env"}, []string{"env-name-collision"}},
+		{"flag_collision", []string{"--flag=a-b", "--flag=a_b"}, []string{"env-name-collision"}},
+		{"suspicious", []string{"--flag=path", "--flag=name", "--flag-name-env-name=ARGONAUT_NAME"}, []string{"suspicious-env-name", "suspicious-env-name"}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var got []string
+			for _, issue := range LintSpec(append([]string{"--shell-type=sh"}, tc.args...), testEnv(nil)) {
+				got = append(got, issue.Code)
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Fatalf("got %v want %v", got, tc.want)
+			}
+		})
+	}
+}
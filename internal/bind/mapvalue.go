@@ -0,0 +1,203 @@
+package bind
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+var AllowedMapDuplicates = []string{"error", "last-wins", "collect"}
+
+var AllowedMapOutputs = []string{"json", "vars", "assoc"}
+
+func checkMapOptions(duplicate string, output string, flag string) error {
+	if !checkInStringSlice(duplicate, AllowedMapDuplicates) {
+		return fmt.Errorf("invalid map duplicate policy: %s for flag %s, allowed policies are: %v", duplicate, flag, AllowedMapDuplicates)
+	}
+	if !checkInStringSlice(output, AllowedMapOutputs) {
+		return fmt.Errorf("invalid map output: %s for flag %s, allowed outputs are: %v", output, flag, AllowedMapOutputs)
+	}
+	return nil
+}
+
+// splitMapEntry splits a "key=value" input of a map flag. The value may contain '='.
+func splitMapEntry(entry string) (string, string, bool) {
+	key, value, found := strings.Cut(entry, "=")
+	key = strings.TrimSpace(key)
+	if !found || key == "" {
+		return "", "", false
+	}
+	return key, value, true
+}
+
+// ParseMapValues validates the repeated "key=value" inputs of a map flag against the allowed keys
+// and applies the duplicate key policy. The result is still a list of "key=value" entries,
+// where a key only appears more than once with the "collect" policy.
+func ParseMapValues(duplicate string, allowedKeys []string, rawValues []string, flag string) ([]string, error) {
+	if rawValues == nil {
+		return nil, nil
+	}
+	var keys []string
+	values := make(map[string][]string)
+	for _, raw := range rawValues {
+		key, value, ok := splitMapEntry(raw)
+		if !ok {
+			return nil, fmt.Errorf("invalid map value: %s for flag %s, expected key=value", raw, flag)
+		}
+		if len(allowedKeys) > 0 && !checkInStringSlice(key, allowedKeys) {
+			return nil, fmt.Errorf("key %s for flag %s is not in allowed keys %v", key, flag, allowedKeys)
+		}
+		if _, exists := values[key]; !exists {
+			keys = append(keys, key)
+			values[key] = []string{value}
+			continue
+		}
+		switch duplicate {
+		case "error":
+			return nil, fmt.Errorf("duplicate key %s for flag %s", key, flag)
+		case "last-wins":
+			values[key] = []string{value}
+		case "collect":
+			values[key] = append(values[key], value)
+		default:
+			return nil, fmt.Errorf("unsupported map duplicate policy: %s for flag %s", duplicate, flag)
+		}
+	}
+	result := []string{}
+	for _, key := range keys {
+		for _, value := range values[key] {
+			result = append(result, key+"="+value)
+		}
+	}
+	return result, nil
+}
+
+// groupMapValues groups the entries returned by ParseMapValues by key, keys are sorted.
+func groupMapValues(entries []string) ([]string, map[string][]string) {
+	grouped := make(map[string][]string)
+	for _, entry := range entries {
+		key, value, _ := splitMapEntry(entry)
+		grouped[key] = append(grouped[key], value)
+	}
+	keys := make([]string, 0, len(grouped))
+	for key := range grouped {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys, grouped
+}
+
+// calcMapKeyEnvSuffix converts a map key to the suffix of its per-key variable, e.g. "a.b-c" -> "A_B_C".
+func calcMapKeyEnvSuffix(key string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' {
+			return r - 'a' + 'A'
+		}
+		if (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') || r == '_' {
+			return r
+		}
+		return '_'
+	}, key)
+}
+
+// envVarAssignment is a single variable assignment before it is rendered for a shell type.
+type envVarAssignment struct {
+	Name  string
+	Value string
+}
+
+// mapVarAssignments renders a map flag with the "json" or "vars" map output. With the "collect" policy
+// every key holds a list, which is a JSON array for the "json" output and is joined
+// using the multi format of the flag otherwise.
+func mapVarAssignments(varName string, spec *FlagSpec) ([]envVarAssignment, error) {
+	keys, grouped := groupMapValues(spec.Value)
+	collect := spec.MapDuplicate == "collect"
+	switch spec.MapOutput {
+	case "json":
+		var data []byte
+		var err error
+		if collect {
+			data, err = json.Marshal(grouped)
+		} else {
+			single := make(map[string]string, len(grouped))
+			for key, values := range grouped {
+				single[key] = values[0]
+			}
+			data, err = json.Marshal(single)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal map values to json: %w", err)
+		}
+		return []envVarAssignment{{varName, string(data)}}, nil
+	case "vars":
+		var assignments []envVarAssignment
+		// the suffixes are upper case, so keys differing in case collide for every shell type
+		owners := make(map[string]string, len(keys))
+		for _, key := range keys {
+			name := varName + "_" + calcMapKeyEnvSuffix(key)
+			if other, exists := owners[name]; exists {
+				return nil, fmt.Errorf("keys %s and %s map to the same environment variable %s", other, key, name)
+			}
+			owners[name] = key
+			val, err := joinMapValues(spec, grouped[key])
+			if err != nil {
+				return nil, err
+			}
+			assignments = append(assignments, envVarAssignment{name, val})
+		}
+		return assignments, nil
+	default:
+		return nil, fmt.Errorf("unsupported map output: %s", spec.MapOutput)
+	}
+}
+
+func joinMapValues(spec *FlagSpec, values []string) (string, error) {
+	if spec.MapDuplicate == "collect" {
+		return OutputMultiValues(spec.MultiFormat, values)
+	}
+	return values[0], nil
+}
+
+// exportMapVar renders a map flag according to its map output.
+func exportMapVar(shellType ShellType, cmdSpec *CmdSpec, varName string, spec *FlagSpec) ([]string, error) {
+	if spec.MapOutput == "assoc" {
+		if shellType != ShellTypeSh {
+			return nil, fmt.Errorf("map output 'assoc' is only supported by shell type %s", ShellTypeSh)
+		}
+		if spec.Scope != ScopeShell {
+			return nil, fmt.Errorf("map output 'assoc' requires scope %s, bash associative arrays cannot be exported to child processes", ScopeShell)
+		}
+		keys, grouped := groupMapValues(spec.Value)
+		var items []string
+		for _, key := range keys {
+			val, err := joinMapValues(spec, grouped[key])
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, fmt.Sprintf("[%s]=%s", buildShellLiteral(key), buildShellLiteral(val)))
+		}
+		declare := "declare"
+		if cmdSpec.ShDeclare == "local" || cmdSpec.ShDeclare == "typeset" {
+			declare = cmdSpec.ShDeclare
+		}
+		opts := "-A"
+		if spec.Readonly {
+			opts += "r"
+		}
+		return []string{fmt.Sprintf("%s %s %s=(%s)", declare, opts, varName, strings.Join(items, " "))}, nil
+	}
+	assignments, err := mapVarAssignments(varName, spec)
+	if err != nil {
+		return nil, err
+	}
+	var lines []string
+	for _, a := range assignments {
+		varLines, err := exportEnvVar(shellType, cmdSpec, a.Name, a.Value, spec.Scope, spec.Readonly)
+		if err != nil {
+			return nil, err
+		}
+		lines = append(lines, varLines...)
+	}
+	return lines, nil
+}
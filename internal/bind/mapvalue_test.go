@@ -0,0 +1,62 @@
+package bind
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseMapValues(t *testing.T) {
+	tests := []struct {
+		name        string
+		duplicate   string
+		allowedKeys []string
+		in          []string
+		want        []string
+		wantErr     bool
+	}{
+		{"nil", "error", nil, nil, nil, false},
+		{"empty", "error", nil, []string{}, []string{}, false},
+		{"value_with_equal", "error", nil, []string{"a=b=c"}, []string{"a=b=c"}, false},
+		{"empty_value", "error", nil, []string{"a="}, []string{"a="}, false},
+		{"trim_key", "error", nil, []string{" a =b"}, []string{"a=b"}, false},
+		{"missing_equal", "error", nil, []string{"a"}, nil, true},
+		{"empty_key", "error", nil, []string{"=b"}, nil, true},
+		{"duplicate_error", "error", nil, []string{"a=1", "a=2"}, nil, true},
+		{"duplicate_last_wins", "last-wins", nil, []string{"a=1", "b=3", "a=2"}, []string{"a=2", "b=3"}, false},
+		{"duplicate_collect", "collect", nil, []string{"a=1", "b=3", "a=2"}, []string{"a=1", "a=2", "b=3"}, false},
+		{"allowed_keys", "error", []string{"a", "b"}, []string{"b=1"}, []string{"b=1"}, false},
+		{"not_allowed_key", "error", []string{"a", "b"}, []string{"c=1"}, nil, true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := ParseMapValues(tc.duplicate, tc.allowedKeys, tc.in, "flag")
+			if tc.wantErr {
+				if err == nil {
+					t.Fatalf("expected error for %v, got %v", tc.in, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error for %v: %v", tc.in, err)
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Fatalf("got %#v want %#v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestCalcMapKeyEnvSuffix(t *testing.T) {
+	tests := map[string]string{
+		"env":   "ENV",
+		"a.b-c": "A_B_C",
+		"Key_1": "KEY_1",
+		"键":     "_",
+	}
+	for in, want := range tests {
+		if got := calcMapKeyEnvSuffix(in); got != want {
+			t.Fatalf("calcMapKeyEnvSuffix(%q): got %q want %q", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,30 @@
+package bind
+
+import (
+	"fmt"
+	"strings"
+)
+
+// buildNushellLiteral renders s as a nushell string. Single-quoted strings have no escapes
+// and may span lines; a value containing a single quote becomes a raw string r#'...'#,
+// with as many '#' as needed so the value does not contain the closing delimiter.
+func buildNushellLiteral(s string) string {
+	if !strings.Contains(s, "'") {
+		return "'" + s + "'"
+	}
+	hashes := "#"
+	for strings.Contains(s, "'"+hashes) {
+		hashes += "#"
+	}
+	return "r" + hashes + "'" + s + "'" + hashes
+}
+
+// exportEnvVarNushell renders a load-env record, nushell has no unexported variable which
+// survives the sourced output, so the shell and env scopes both set the environment.
+func exportEnvVarNushell(varName string, val string) string {
+	return fmt.Sprintf("load-env { %s: %s }", varName, buildNushellLiteral(val))
+}
+
+func unsetEnvVarNushell(varName string) string {
+	return fmt.Sprintf("hide-env -i %s", varName)
+}
@@ -0,0 +1,24 @@
+package bind
+
+import "testing"
+
+func TestBuildNushellLiteral(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"empty", "", "''"},
+		{"plain", `a "b" $c \d`, `'a "b" $c \d'`},
+		{"newline", "a\nb", "'a\nb'"},
+		{"single_quote", "it's", "r#'it's'#"},
+		{"closing_delimiter", "a'#b'##", "r###'a'#b'##'###"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := buildNushellLiteral(tc.in); got != tc.want {
+				t.Fatalf("got %q want %q", got, tc.want)
+			}
+		})
+	}
+}
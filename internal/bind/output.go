@@ -0,0 +1,227 @@
+package bind
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// AllowedOutputs are the output targets: "shell" prints statements for the shell type,
+// the others render the resolved values for a CI system or as an env file.
+var AllowedOutputs = []string{"shell", "github-env", "github-output", "gitlab-dotenv", "dotenv", "docker-env", "systemd-env"}
+
+// outputFileEnvs are the environment variables naming the file an output target appends to.
+var outputFileEnvs = map[string]string{
+	"github-env":    "GITHUB_ENV",
+	"github-output": "GITHUB_OUTPUT",
+}
+
+// githubDelimiter delimits multi-line values of the GitHub file commands, it gets a numeric
+// suffix when a value contains it.
+const githubDelimiter = "ARGONAUT_EOF"
+
+func isShellOutput(output string) bool {
+	return output == "" || output == "shell"
+}
+
+// outputEnvNameRule returns the name rule of the output target, or of the shell type for the "shell" output.
+// GitHub ignores the variables overriding its default GITHUB_* and RUNNER_* variables,
+// and step output names may contain '-' as well.
+func outputEnvNameRule(shellType ShellType, output string) envNameRule {
+	rule := envNameRule{
+		target:         fmt.Sprintf("output %s", output),
+		isRune:         isIdentifierRune,
+		noLeadingDigit: true,
+	}
+	switch output {
+	case "github-env":
+		rule.reservedPrefixes = []string{"GITHUB_", "RUNNER_"}
+	case "github-output":
+		rule.isRune = func(r rune) bool {
+			return isIdentifierRune(r) || r == '-'
+		}
+	case "gitlab-dotenv", "dotenv", "docker-env", "systemd-env":
+	default:
+		return shellEnvNameRule(shellType)
+	}
+	return rule
+}
+
+// flagAssignments returns the variables of a flag with their values rendered by the multi format
+// or the map output, shared by all output targets. The "assoc" map output is only supported by
+// the "shell" output of sh-like shells, which renders it itself.
+func flagAssignments(varName string, fs *FlagSpec) ([]envVarAssignment, error) {
+	if fs.Type == FlagTypeMap {
+		if fs.MapOutput == "assoc" {
+			return nil, fmt.Errorf("map output 'assoc' is only supported by shell type %s", ShellTypeSh)
+		}
+		return mapVarAssignments(varName, fs)
+	}
+	val, err := OutputMultiValues(fs.MultiFormat, fs.Value)
+	if err != nil {
+		return nil, err
+	}
+	return []envVarAssignment{{varName, val}}, nil
+}
+
+// buildGithubFileCommand renders a NAME=value line for $GITHUB_ENV or $GITHUB_OUTPUT,
+// or the NAME<<DELIMITER form for a multi-line value.
+func buildGithubFileCommand(a envVarAssignment) string {
+	if !strings.ContainsAny(a.Value, "\r\n") {
+		return fmt.Sprintf("%s=%s", a.Name, a.Value)
+	}
+	delimiter := githubDelimiter
+	for i := 1; strings.Contains(a.Value, delimiter); i++ {
+		delimiter = fmt.Sprintf("%s_%d", githubDelimiter, i)
+	}
+	return fmt.Sprintf("%s<<%s\n%s\n%s", a.Name, delimiter, a.Value, delimiter)
+}
+
+// buildGitlabDotenvLine renders a line of a GitLab dotenv report, which has no quoting
+// and does not support multi-line values.
+func buildGitlabDotenvLine(a envVarAssignment) (string, error) {
+	if strings.ContainsAny(a.Value, "\r\n") {
+		return "", fmt.Errorf("value of %s contains line breaks, which gitlab dotenv reports do not support", a.Name)
+	}
+	return fmt.Sprintf("%s=%s", a.Name, a.Value), nil
+}
+
+// isPlainEnvFileValue reports whether the value can be written unquoted in every env file format.
+func isPlainEnvFileValue(val string) bool {
+	for _, r := range val {
+		if !isIdentifierRune(r) && !strings.ContainsRune("./:@%+,-", r) {
+			return false
+		}
+	}
+	return true
+}
+
+// buildDotenvLine renders a .env line as read by docker compose and python-dotenv. Single quotes
+// keep the value literally, a value containing a single quote, a backslash or a line break is
+// double-quoted, where '\', '"' and line breaks are escaped. In double quotes docker compose
+// interpolates $VAR and ${VAR} and python-dotenv ${VAR}, with no escape for '$' shared by both,
+// so such a value must not contain '$'.
+func buildDotenvLine(a envVarAssignment) (string, error) {
+	if isPlainEnvFileValue(a.Value) {
+		return fmt.Sprintf("%s=%s", a.Name, a.Value), nil
+	}
+	if !strings.ContainsAny(a.Value, "'\\\r\n") {
+		return fmt.Sprintf("%s='%s'", a.Name, a.Value), nil
+	}
+	if strings.ContainsRune(a.Value, '$') {
+		return "", fmt.Errorf("value of %s contains '$' and a single quote, a backslash or a line break, which dotenv files cannot hold literally", a.Name)
+	}
+	replacer := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\r", `\r`)
+	return fmt.Sprintf("%s=\"%s\"", a.Name, replacer.Replace(a.Value)), nil
+}
+
+// buildDockerEnvLine renders a line of a docker --env-file, which takes the value literally
+// up to the end of the line.
+func buildDockerEnvLine(a envVarAssignment) (string, error) {
+	if strings.ContainsAny(a.Value, "\r\n") {
+		return "", fmt.Errorf("value of %s contains line breaks, which docker env files do not support", a.Name)
+	}
+	return fmt.Sprintf("%s=%s", a.Name, a.Value), nil
+}
+
+// buildSystemdEnvLine renders a line of a systemd EnvironmentFile=, which does not expand variables.
+// Single quotes are literal and may span lines; in double quotes '\\' and '"' are escaped.
+func buildSystemdEnvLine(a envVarAssignment) string {
+	if isPlainEnvFileValue(a.Value) {
+		return fmt.Sprintf("%s=%s", a.Name, a.Value)
+	}
+	if !strings.ContainsRune(a.Value, '\'') {
+		return fmt.Sprintf("%s='%s'", a.Name, a.Value)
+	}
+	replacer := strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+	return fmt.Sprintf("%s=\"%s\"", a.Name, replacer.Replace(a.Value))
+}
+
+func buildOutputLine(output string, a envVarAssignment) (string, error) {
+	if strings.ContainsRune(a.Value, 0) {
+		return "", fmt.Errorf("value of %s contains NUL characters, which output %s cannot hold", a.Name, output)
+	}
+	switch output {
+	case "github-env", "github-output":
+		return buildGithubFileCommand(a), nil
+	case "gitlab-dotenv":
+		return buildGitlabDotenvLine(a)
+	case "dotenv":
+		return buildDotenvLine(a)
+	case "docker-env":
+		return buildDockerEnvLine(a)
+	case "systemd-env":
+		return buildSystemdEnvLine(a), nil
+	default:
+		return "", fmt.Errorf("unsupported output: %s", output)
+	}
+}
+
+// WriteOutputFile writes the output to the file given by --output-file instead of stdout.
+// A new file is only readable by the user since env files often hold secrets.
+func WriteOutputFile(path string, output string) error {
+	if err := os.WriteFile(path, []byte(output+"\n"), 0o600); err != nil {
+		return fmt.Errorf("cannot write output file %s: %w", path, err)
+	}
+	return nil
+}
+
+// appendOutputFile appends the lines to the file named by the environment variable of the output target.
+func appendOutputFile(output string, lines []string, env *Env) error {
+	envName := outputFileEnvs[output]
+	path := env.getenv(envName)
+	if path == "" {
+		return fmt.Errorf("output %s requires the environment variable %s", output, envName)
+	}
+	if len(lines) == 0 {
+		return nil
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o644)
+	if err != nil {
+		return fmt.Errorf("cannot open %s: %w", path, err)
+	}
+	defer f.Close()
+	if _, err := f.WriteString(strings.Join(lines, "\n") + "\n"); err != nil {
+		return fmt.Errorf("cannot write %s: %w", path, err)
+	}
+	return nil
+}
+
+// exportOutputVars renders the flags for an output target other than "shell". Scopes do not apply,
+// and omitted flags are skipped with --unset-missing since the targets cannot unset a variable.
+// Targets with a file environment variable append to it and return an empty output.
+func exportOutputVars(spec *CmdSpec, env *Env) (string, error) {
+	var keys []string
+	for k := range spec.Flags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var lines []string
+	for _, key := range keys {
+		fs := spec.Flags[key]
+		if fs == nil || fs.Missing && (fs.UnsetMissing || spec.UnsetMissing) {
+			continue
+		}
+		varName := fs.VarName
+		if varName == "" {
+			varName = calcEnvName(key, fs.EnvName, spec.EnvPrefix)
+		}
+		assignments, err := flagAssignments(varName, fs)
+		if err != nil {
+			return "", fmt.Errorf("flag %s: %w", key, err)
+		}
+		for _, a := range assignments {
+			line, err := buildOutputLine(spec.Output, a)
+			if err != nil {
+				return "", fmt.Errorf("flag %s: %w", key, err)
+			}
+			lines = append(lines, line)
+		}
+	}
+	if _, ok := outputFileEnvs[spec.Output]; ok {
+		return "", appendOutputFile(spec.Output, lines, env)
+	}
+	return strings.Join(lines, "\n"), nil
+}
@@ -0,0 +1,151 @@
+package bind
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestBuildGithubFileCommand(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		want  string
+	}{
+		{"plain", "a b=c", "NAME=a b=c"},
+		{"empty", "", "NAME="},
+		{"multiline", "a\nb", "NAME<<ARGONAUT_EOF\na\nb\nARGONAUT_EOF"},
+		{"delimiter_in_value", "ARGONAUT_EOF\nARGONAUT_EOF_1", "NAME<<ARGONAUT_EOF_2\nARGONAUT_EOF\nARGONAUT_EOF_1\nARGONAUT_EOF_2"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := buildGithubFileCommand(envVarAssignment{"NAME", tc.value})
+			if got != tc.want {
+				t.Fatalf("got %q want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestExportOutputVarsGithubEnv(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "github_env")
+	if err := os.WriteFile(path, []byte("EXISTING=1\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	env := testEnv(map[string]string{"GITHUB_ENV": path})
+	spec := &CmdSpec{
+		Output: "github-env",
+		Flags: map[string]*FlagSpec{
+			"name":  {MultiFormat: []string{"comma"}, Value: []string{"alice"}},
+			"notes": {MultiFormat: []string{"comma"}, Value: []string{"a\nb"}},
+		},
+	}
+	out, err := exportEnvVars(spec, env)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if out != "" {
+		t.Fatalf("expected empty output, got %q", out)
+	}
+	data, _ := os.ReadFile(path)
+	if want := "EXISTING=1\nNAME=alice\nNOTES<<ARGONAUT_EOF\na\nb\nARGONAUT_EOF\n"; string(data) != want {
+		t.Fatalf("got %q want %q", data, want)
+	}
+
+	if _, err := exportEnvVars(spec, testEnv(map[string]string{"GITHUB_ENV": ""})); err == nil {
+		t.Fatalf("expected error without GITHUB_ENV")
+	}
+}
+
+func TestExportOutputVarsGitlabDotenv(t *testing.T) {
+	spec := &CmdSpec{
+		Output: "gitlab-dotenv",
+		Flags: map[string]*FlagSpec{
+			"name": {MultiFormat: []string{"comma"}, Value: []string{"a", "b"}, Multi: true},
+		},
+	}
+	out, err := exportEnvVars(spec, testEnv(nil))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if out != "NAME=a,b" {
+		t.Fatalf("got %q", out)
+	}
+	spec.Flags["name"].Value = []string{"a\nb"}
+	if _, err := exportEnvVars(spec, testEnv(nil)); err == nil {
+		t.Fatalf("expected error for multi-line value")
+	}
+}
+
+func TestBuildEnvFileLines(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		dotenv  string
+		systemd string
+	}{
+		{"plain", "a.b:c/1", "NAME=a.b:c/1", "NAME=a.b:c/1"},
+		{"empty", "", "NAME=", "NAME="},
+		{"space", " a b ", "NAME=' a b '", "NAME=' a b '"},
+		{"dollar", "$HOME", "NAME='$HOME'", "NAME='$HOME'"},
+		{"single_quote", `it's "x" \`, `NAME="it's \"x\" \\"`, `NAME="it's \"x\" \\"`},
+		{"backslash", `C:\dir`, `NAME="C:\\dir"`, `NAME='C:\dir'`},
+		{"multiline", "a\nb", `NAME="a\nb"`, "NAME='a\nb'"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			a := envVarAssignment{"NAME", tc.value}
+			if got, err := buildDotenvLine(a); err != nil || got != tc.dotenv {
+				t.Fatalf("dotenv: got %q, %v want %q", got, err, tc.dotenv)
+			}
+			if got := buildSystemdEnvLine(a); got != tc.systemd {
+				t.Fatalf("systemd: got %q want %q", got, tc.systemd)
+			}
+		})
+	}
+	if got, err := buildDotenvLine(envVarAssignment{"NAME", "it's $HOME"}); err == nil {
+		t.Fatalf("dotenv: got %q want error for a value with '$' and a single quote", got)
+	}
+	if got, err := buildDockerEnvLine(envVarAssignment{"NAME", `it's "$x"`}); err != nil || got != `NAME=it's "$x"` {
+		t.Fatalf("docker: got %q, %v", got, err)
+	}
+	if _, err := buildDockerEnvLine(envVarAssignment{"NAME", "a\nb"}); err == nil {
+		t.Fatalf("docker: expected error for multi-line value")
+	}
+}
+
+func TestWriteOutputFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.env")
+	if err := os.WriteFile(path, []byte("OLD=1\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := WriteOutputFile(path, "NAME=a"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	data, _ := os.ReadFile(path)
+	if string(data) != "NAME=a\n" {
+		t.Fatalf("got %q", data)
+	}
+}
+
+func TestOutputEnvNameRule(t *testing.T) {
+	tests := []struct {
+		output string
+		name   string
+		valid  bool
+	}{
+		{"github-env", "NAME", true},
+		{"github-env", "GITHUB_TOKEN", false},
+		{"github-env", "runner_temp", false},
+		{"github-env", "with-dash", false},
+		{"github-output", "with-dash", true},
+		{"github-output", "1st", false},
+		{"gitlab-dotenv", "with-dash", false},
+	}
+	for _, tc := range tests {
+		err := checkEnvName(outputEnvNameRule(ShellTypeSh, tc.output), tc.name)
+		if (err == nil) != tc.valid {
+			t.Fatalf("%s %q: got error %v want valid %v", tc.output, tc.name, err, tc.valid)
+		}
+	}
+}
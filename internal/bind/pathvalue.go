@@ -0,0 +1,132 @@
+package bind
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+var AllowedPathChecks = []string{"must-exist", "must-not-exist", "readable", "writable", "executable"}
+
+var AllowedPathNormalizations = []string{"home", "abs", "clean"}
+
+func isPathFlagType(flagType FlagType) bool {
+	return flagType == FlagTypeFile || flagType == FlagTypeDir || flagType == FlagTypePath
+}
+
+func checkPathOptions(checks []string, normalizations []string, flag string) error {
+	for _, check := range checks {
+		if !checkInStringSlice(check, AllowedPathChecks) {
+			return fmt.Errorf("invalid path check: %s for flag %s, allowed checks are: %v", check, flag, AllowedPathChecks)
+		}
+	}
+	if checkInStringSlice("must-exist", checks) && checkInStringSlice("must-not-exist", checks) {
+		return fmt.Errorf("path checks 'must-exist' and 'must-not-exist' for flag %s cannot be combined", flag)
+	}
+	if checkInStringSlice("must-not-exist", checks) {
+		for _, check := range []string{"readable", "executable"} {
+			if checkInStringSlice(check, checks) {
+				return fmt.Errorf("path checks 'must-not-exist' and '%s' for flag %s cannot be combined", check, flag)
+			}
+		}
+	}
+	for _, normalization := range normalizations {
+		if !checkInStringSlice(normalization, AllowedPathNormalizations) {
+			return fmt.Errorf("invalid path normalization: %s for flag %s, allowed normalizations are: %v", normalization, flag, AllowedPathNormalizations)
+		}
+	}
+	return nil
+}
+
+// expandHome replaces a leading "~" with the home directory of the user in env.
+// "~user" is left untouched.
+func expandHome(path string, env *Env) (string, error) {
+	if path != "~" && !strings.HasPrefix(path, "~/") && !(runtime.GOOS == "windows" && strings.HasPrefix(path, `~\`)) {
+		return path, nil
+	}
+	home, err := env.userHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return home + path[1:], nil
+}
+
+// NormalizePath applies the normalizations in the fixed order home, abs, clean.
+// The relative path is resolved against the working directory of the caller, which argonaut inherits.
+func NormalizePath(path string, normalizations []string, flag string, env *Env) (string, error) {
+	if path == "" {
+		return path, nil
+	}
+	var err error
+	if checkInStringSlice("home", normalizations) {
+		if path, err = expandHome(path, env); err != nil {
+			return "", fmt.Errorf("cannot expand home directory of path %s for flag %s: %w", path, flag, err)
+		}
+	}
+	if checkInStringSlice("abs", normalizations) {
+		if path, err = filepath.Abs(path); err != nil {
+			return "", fmt.Errorf("cannot make path %s absolute for flag %s: %w", path, flag, err)
+		}
+	}
+	if checkInStringSlice("clean", normalizations) {
+		path = filepath.Clean(path)
+	}
+	return path, nil
+}
+
+func isReadable(path string) bool {
+	f, err := os.Open(path)
+	if err != nil {
+		return false
+	}
+	f.Close()
+	return true
+}
+
+// CheckPath validates the path value of a file, dir or path flag. An existing path must match the
+// flag type. A path which does not exist is writable when its parent directory is writable.
+func CheckPath(flagType FlagType, path string, checks []string, flag string, env *Env) error {
+	if path == "" {
+		return nil
+	}
+	info, err := os.Stat(path)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("cannot access path %s for flag %s: %w", path, flag, err)
+	}
+	exists := err == nil
+	if !exists {
+		if checkInStringSlice("must-exist", checks) || checkInStringSlice("readable", checks) || checkInStringSlice("executable", checks) {
+			return fmt.Errorf("path %s for flag %s does not exist", path, flag)
+		}
+		if checkInStringSlice("writable", checks) {
+			parent := filepath.Dir(path)
+			if parentInfo, err := os.Stat(parent); err != nil || !parentInfo.IsDir() || !isWritable(parent, parentInfo) {
+				return fmt.Errorf("path %s for flag %s is not writable", path, flag)
+			}
+		}
+		return nil
+	}
+	if checkInStringSlice("must-not-exist", checks) {
+		return fmt.Errorf("path %s for flag %s already exists", path, flag)
+	}
+	if flagType == FlagTypeFile && info.IsDir() {
+		return fmt.Errorf("path %s for flag %s is a directory, not a file", path, flag)
+	}
+	if flagType == FlagTypeDir && !info.IsDir() {
+		return fmt.Errorf("path %s for flag %s is not a directory", path, flag)
+	}
+	if checkInStringSlice("readable", checks) && !isReadable(path) {
+		return fmt.Errorf("path %s for flag %s is not readable", path, flag)
+	}
+	if checkInStringSlice("writable", checks) && !isWritable(path, info) {
+		return fmt.Errorf("path %s for flag %s is not writable", path, flag)
+	}
+	if checkInStringSlice("executable", checks) && !isExecutable(path, info, env) {
+		return fmt.Errorf("path %s for flag %s is not executable", path, flag)
+	}
+	return nil
+}
@@ -0,0 +1,15 @@
+//go:build !unix && !windows
+
+package bind
+
+import "io/fs"
+
+// isWritable falls back to the permission bits where the system cannot be asked for the access.
+func isWritable(path string, info fs.FileInfo) bool {
+	return info.Mode().Perm()&0o222 != 0
+}
+
+// isExecutable falls back to the permission bits as well.
+func isExecutable(path string, info fs.FileInfo, env *Env) bool {
+	return info.Mode().Perm()&0o111 != 0
+}
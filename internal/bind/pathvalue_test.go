@@ -0,0 +1,88 @@
+package bind
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestNormalizePath(t *testing.T) {
+	home := t.TempDir()
+	env := testEnv(map[string]string{"HOME": home, "USERPROFILE": home, "home": home})
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name           string
+		in             string
+		normalizations []string
+		want           string
+	}{
+		{"empty", "", []string{"home", "abs", "clean"}, ""},
+		{"none", "~/a/../b", nil, "~/a/../b"},
+		{"home", "~/a", []string{"home"}, home + "/a"},
+		{"home_only", "~", []string{"home"}, home},
+		{"other_user", "~user/a", []string{"home"}, "~user/a"},
+		{"clean", "a/./b/../c/", []string{"clean"}, filepath.Clean("a/c")},
+		{"abs", "a", []string{"abs"}, filepath.Join(wd, "a")},
+		{"all", "~/a/../b", []string{"clean", "abs", "home"}, filepath.Join(home, "b")},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := NormalizePath(tc.in, tc.normalizations, "flag", env)
+			if err != nil {
+				t.Fatalf("unexpected error for %q: %v", tc.in, err)
+			}
+			if got != tc.want {
+				t.Fatalf("NormalizePath(%q, %v): got %q want %q", tc.in, tc.normalizations, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestCheckPath(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "file")
+	if err := os.WriteFile(file, []byte("x"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	script := filepath.Join(dir, "script")
+	if err := os.WriteFile(script, []byte("x"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	missing := filepath.Join(dir, "missing")
+	tests := []struct {
+		name     string
+		flagType FlagType
+		path     string
+		checks   []string
+		wantErr  bool
+	}{
+		{"empty", FlagTypeFile, "", []string{"must-exist"}, false},
+		{"file_exists", FlagTypeFile, file, []string{"must-exist", "readable", "writable"}, false},
+		{"file_is_dir", FlagTypeFile, dir, nil, true},
+		{"dir_is_file", FlagTypeDir, file, nil, true},
+		{"dir_writable", FlagTypeDir, dir, []string{"writable"}, false},
+		{"path_any", FlagTypePath, dir, []string{"must-exist"}, false},
+		{"missing", FlagTypePath, missing, []string{"must-exist"}, true},
+		{"missing_readable", FlagTypePath, missing, []string{"readable"}, true},
+		{"missing_ok", FlagTypeFile, missing, []string{"must-not-exist", "writable"}, false},
+		{"missing_parent", FlagTypeFile, filepath.Join(missing, "file"), []string{"writable"}, true},
+		{"exists", FlagTypeFile, file, []string{"must-not-exist"}, true},
+		{"executable", FlagTypeFile, script, []string{"executable"}, runtime.GOOS == "windows"},
+		{"not_executable", FlagTypeFile, file, []string{"executable"}, true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := CheckPath(tc.flagType, tc.path, tc.checks, "flag", OSEnv())
+			if tc.wantErr && err == nil {
+				t.Fatalf("expected error for %q with checks %v", tc.path, tc.checks)
+			}
+			if !tc.wantErr && err != nil {
+				t.Fatalf("unexpected error for %q with checks %v: %v", tc.path, tc.checks, err)
+			}
+		})
+	}
+}
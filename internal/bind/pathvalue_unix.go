@@ -0,0 +1,21 @@
+//go:build unix
+
+package bind
+
+import (
+	"io/fs"
+
+	"golang.org/x/sys/unix"
+)
+
+// isWritable asks the kernel whether the user may write the file or create entries in the directory,
+// which also covers read-only mounts, without writing anything.
+func isWritable(path string, info fs.FileInfo) bool {
+	return unix.Access(path, unix.W_OK) == nil
+}
+
+// isExecutable asks the kernel whether the user may execute the file or search the directory, which
+// covers root, the group and other permission bits and noexec mounts.
+func isExecutable(path string, info fs.FileInfo, env *Env) bool {
+	return unix.Access(path, unix.X_OK) == nil
+}
@@ -0,0 +1,55 @@
+//go:build windows
+
+package bind
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/sys/windows"
+)
+
+// isWritable opens an existing file for writing without truncating it, and a directory with the right
+// to add files to it, so the ACLs are checked without writing anything.
+func isWritable(path string, info fs.FileInfo) bool {
+	if !info.IsDir() {
+		f, err := os.OpenFile(path, os.O_WRONLY, 0)
+		if err != nil {
+			return false
+		}
+		f.Close()
+		return true
+	}
+	name, err := windows.UTF16PtrFromString(path)
+	if err != nil {
+		return false
+	}
+	// FILE_ADD_FILE of a directory has the value of FILE_WRITE_DATA
+	h, err := windows.CreateFile(name, windows.FILE_WRITE_DATA, windows.FILE_SHARE_READ|windows.FILE_SHARE_WRITE|windows.FILE_SHARE_DELETE,
+		nil, windows.OPEN_EXISTING, windows.FILE_FLAG_BACKUP_SEMANTICS, 0)
+	if err != nil {
+		return false
+	}
+	windows.CloseHandle(h)
+	return true
+}
+
+// isExecutable reports whether the file has an extension of $PATHEXT in env.
+func isExecutable(path string, info fs.FileInfo, env *Env) bool {
+	if info.IsDir() {
+		return false
+	}
+	ext := strings.ToLower(filepath.Ext(path))
+	pathExt := env.getenv("PATHEXT")
+	if pathExt == "" {
+		pathExt = ".com;.exe;.bat;.cmd"
+	}
+	for _, e := range filepath.SplitList(strings.ToLower(pathExt)) {
+		if e != "" && e == ext {
+			return true
+		}
+	}
+	return false
+}
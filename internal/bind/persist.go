@@ -0,0 +1,162 @@
+package bind
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+var AllowedShPersists = []string{"none", "profile", "bashrc", "zshenv", "environment.d"}
+
+// environmentDFileName is the file in ~/.config/environment.d holding the managed blocks.
+const environmentDFileName = "60-argonaut.conf"
+
+// ShPersistFile returns the file written by the persist target, the home and config directories are taken from env.
+func ShPersistFile(target string, env *Env) (string, error) {
+	home, err := env.userHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("cannot find home directory: %w", err)
+	}
+	switch target {
+	case "profile":
+		return filepath.Join(home, ".profile"), nil
+	case "bashrc":
+		return filepath.Join(home, ".bashrc"), nil
+	case "zshenv":
+		if zdotdir := env.getenv("ZDOTDIR"); zdotdir != "" {
+			return filepath.Join(zdotdir, ".zshenv"), nil
+		}
+		return filepath.Join(home, ".zshenv"), nil
+	case "environment.d":
+		configHome := env.getenv("XDG_CONFIG_HOME")
+		if configHome == "" {
+			configHome = filepath.Join(home, ".config")
+		}
+		return filepath.Join(configHome, "environment.d", environmentDFileName), nil
+	default:
+		return "", fmt.Errorf("unsupported sh persist target: %s", target)
+	}
+}
+
+func checkPersistName(name string) error {
+	if name == "" || strings.ContainsAny(name, "\r\n") {
+		return fmt.Errorf("invalid name %q for the persisted block, it must be a non-empty single line", name)
+	}
+	return nil
+}
+
+func persistBlockMarkers(name string) (string, string) {
+	return fmt.Sprintf("# >>> argonaut: %s >>>", name), fmt.Sprintf("# <<< argonaut: %s <<<", name)
+}
+
+// buildEnvironmentDLine renders a KEY=VALUE line of environment.d, which has no quoting but
+// expands $VAR references, so values which cannot be written literally are rejected.
+func buildEnvironmentDLine(varName string, val string) (string, error) {
+	if strings.ContainsAny(val, "\r\n$\\\"'") {
+		return "", fmt.Errorf("value of %s cannot be written to environment.d, it contains a line break, '$', '\\' or quotes", varName)
+	}
+	if strings.TrimSpace(val) != val {
+		return "", fmt.Errorf("value of %s cannot be written to environment.d, it has leading or trailing whitespace", varName)
+	}
+	return fmt.Sprintf("%s=%s", varName, val), nil
+}
+
+// renderPersistBlock renders the managed block of the command, an empty block is returned when
+// nothing is persisted so the block is removed.
+func renderPersistBlock(target string, name string, assignments []envVarAssignment) (string, error) {
+	if len(assignments) == 0 {
+		return "", nil
+	}
+	begin, end := persistBlockMarkers(name)
+	lines := []string{
+		begin,
+		fmt.Sprintf("# managed by argonaut, remove with: argonaut unpersist --sh-persist=%s --name=%s", target, buildShellLiteral(name)),
+	}
+	for _, a := range assignments {
+		var line string
+		var err error
+		if target == "environment.d" {
+			line, err = buildEnvironmentDLine(a.Name, a.Value)
+		} else {
+			line, err = exportEnvVarLinuxLike(a.Name, a.Value, true, "assign", false)
+		}
+		if err != nil {
+			return "", err
+		}
+		for _, l := range strings.Split(line, "\n") {
+			if l == begin || l == end {
+				return "", fmt.Errorf("value of %s contains the marker line of the persisted block", a.Name)
+			}
+		}
+		lines = append(lines, line)
+	}
+	lines = append(lines, end)
+	return strings.Join(lines, "\n") + "\n", nil
+}
+
+// replacePersistBlock replaces the managed block of the command in content, or appends it when
+// the content has no such block. An empty block removes the existing one.
+func replacePersistBlock(content string, name string, block string) (string, error) {
+	begin, end := persistBlockMarkers(name)
+	lines := strings.SplitAfter(content, "\n")
+	start := -1
+	for i, line := range lines {
+		trimmed := strings.TrimRight(line, "\r\n")
+		if start < 0 && trimmed == begin {
+			start = i
+		} else if start >= 0 && trimmed == end {
+			return strings.Join(lines[:start], "") + block + strings.Join(lines[i+1:], ""), nil
+		}
+	}
+	if start >= 0 {
+		return "", fmt.Errorf("the persisted block of %s is not terminated by %q", name, end)
+	}
+	if block == "" {
+		return content, nil
+	}
+	if content != "" && !strings.HasSuffix(content, "\n") {
+		content += "\n"
+	}
+	return content + block, nil
+}
+
+// PersistShVars writes the assignments into the managed block of the command in the file of the target.
+// Running it again with the same assignments leaves the file untouched, without assignments the block is removed.
+func PersistShVars(target string, name string, assignments []envVarAssignment, env *Env) error {
+	if err := checkPersistName(name); err != nil {
+		return err
+	}
+	path, err := ShPersistFile(target, env)
+	if err != nil {
+		return err
+	}
+	block, err := renderPersistBlock(target, name, assignments)
+	if err != nil {
+		return err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("cannot read %s: %w", path, err)
+	}
+	updated, err := replacePersistBlock(string(data), name, block)
+	if err != nil {
+		return fmt.Errorf("cannot update %s: %w", path, err)
+	}
+	if updated == string(data) {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("cannot create directory of %s: %w", path, err)
+	}
+	// write in place instead of renaming a temp file, so a symlinked rc file and its mode are kept
+	if err := os.WriteFile(path, []byte(updated), 0o644); err != nil {
+		return fmt.Errorf("cannot write %s: %w", path, err)
+	}
+	return nil
+}
+
+// UnpersistShVars removes the managed block of the command from the file of the target.
+func UnpersistShVars(target string, name string, env *Env) error {
+	return PersistShVars(target, name, nil, env)
+}
@@ -0,0 +1,91 @@
+package bind
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func persistHomeEnv(t *testing.T) (string, *Env) {
+	home := t.TempDir()
+	return home, testEnv(map[string]string{"HOME": home, "USERPROFILE": home, "home": home})
+}
+
+func TestPersistShVars(t *testing.T) {
+	home, env := persistHomeEnv(t)
+	rc := filepath.Join(home, ".bashrc")
+	original := "alias ll='ls -l'\n"
+	if err := os.WriteFile(rc, []byte(original), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	assignments := []envVarAssignment{{"NAME", "it's"}, {"MULTI", "a\nb"}}
+	want := original +
+		"# >>> argonaut: my.sh >>>\n" +
+		"# managed by argonaut, remove with: argonaut unpersist --sh-persist=bashrc --name='my.sh'\n" +
+		"export NAME='it'\\''s'\n" +
+		"export MULTI='a\nb'\n" +
+		"# <<< argonaut: my.sh <<<\n"
+	for i := 0; i < 2; i++ {
+		if err := PersistShVars("bashrc", "my.sh", assignments, env); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		data, _ := os.ReadFile(rc)
+		if string(data) != want {
+			t.Fatalf("run %d: got %q want %q", i, data, want)
+		}
+	}
+	if info, _ := os.Stat(rc); info.Mode().Perm() != 0o600 {
+		t.Fatalf("mode of rc file changed to %v", info.Mode().Perm())
+	}
+
+	if err := os.WriteFile(rc, []byte(want+"export AFTER=1\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := PersistShVars("bashrc", "my.sh", []envVarAssignment{{"NAME", "new"}}, env); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	data, _ := os.ReadFile(rc)
+	if !strings.Contains(string(data), "export NAME='new'\n# <<< argonaut: my.sh <<<\nexport AFTER=1\n") || strings.Contains(string(data), "MULTI") {
+		t.Fatalf("block not replaced in place: %q", data)
+	}
+
+	if err := UnpersistShVars("bashrc", "my.sh", env); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	data, _ = os.ReadFile(rc)
+	if string(data) != original+"export AFTER=1\n" {
+		t.Fatalf("got %q after removal", data)
+	}
+}
+
+func TestPersistShVarsEnvironmentD(t *testing.T) {
+	home, env := persistHomeEnv(t)
+	if err := PersistShVars("environment.d", "app", []envVarAssignment{{"EDITOR", "vim"}}, env); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(home, ".config", "environment.d", environmentDFileName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "\nEDITOR=vim\n") {
+		t.Fatalf("got %q", data)
+	}
+	for _, val := range []string{"$HOME", "a\nb", `"q"`, " padded"} {
+		if err := PersistShVars("environment.d", "app", []envVarAssignment{{"EDITOR", val}}, env); err == nil {
+			t.Fatalf("expected error for value %q", val)
+		}
+	}
+}
+
+func TestReplacePersistBlockErrors(t *testing.T) {
+	if _, err := replacePersistBlock("# >>> argonaut: app >>>\nexport A=1\n", "app", ""); err == nil {
+		t.Fatalf("expected error for unterminated block")
+	}
+	if _, err := renderPersistBlock("bashrc", "app", []envVarAssignment{{"A", "x\n# <<< argonaut: app <<<\ny"}}); err == nil {
+		t.Fatalf("expected error for value containing the marker")
+	}
+	if err := PersistShVars("bashrc", "a\nb", nil, testEnv(nil)); err == nil {
+		t.Fatalf("expected error for multi-line name")
+	}
+}
@@ -0,0 +1,68 @@
+package bind
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// Resolution is the result of resolving user args against a spec without printing anything.
+type Resolution struct {
+	// Spec holds the resolved values of the flags
+	Spec *CmdSpec
+	// Args are the positional arguments
+	Args []string
+	// Help is set if the help of the command was requested
+	Help bool
+	env  *Env
+}
+
+// Resolve builds the spec from the bind options and resolves the user args against it, userArgs[0] is the
+// command name as in 'bind -- $0 "$@"'. The help of the command is written to env.Stderr when it is requested,
+// errors are returned without being printed.
+func Resolve(bindArgs []string, userArgs []string, env *Env) (*Resolution, error) {
+	if len(userArgs) == 0 {
+		return nil, fmt.Errorf("the user args must start with the command name")
+	}
+	spec, err := collectSpecs(&cobra.Command{Use: "argonaut"}, bindArgs, userArgs, env.quiet())
+	if err != nil {
+		return nil, err
+	} else if spec == nil {
+		return nil, fmt.Errorf("the bind options request the help of bind")
+	}
+	r := &Resolution{Spec: spec, env: env}
+	realCmd := newUserCommand(spec, userArgs, env, func(cmd *cobra.Command, args []string) error {
+		r.Args = args
+		return nil
+	})
+	realCmd.SetHelpFunc(func(c *cobra.Command, s []string) {
+		r.Help = true
+		writeCommandHelp(env.Stderr, c)
+	})
+	realCmd.SilenceErrors = true
+	realCmd.SilenceUsage = true
+	realCmd.SetArgs(userArgs[1:])
+	if err := realCmd.Execute(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Render returns what bind prints for the resolution: the help variable if the help was requested,
+// otherwise the variables of the flags for the shell type and output of the spec. Like bind, it
+// persists the variables of --sh-persist and writes the github-env and github-output files.
+func (r *Resolution) Render() (string, error) {
+	if !r.Help {
+		return exportEnvVars(r.Spec, r.env)
+	}
+	shellType, err := decideShellType(r.Spec.ShellType, r.env)
+	if err != nil {
+		return "", err
+	}
+	lines, err := exportEnvVar(shellType, r.Spec, r.Spec.HelpVar, "true", r.Spec.HelpScope, false)
+	if err != nil {
+		return "", err
+	}
+	return strings.Join(lines, "\n"), nil
+}
@@ -4,7 +4,8 @@ import (
 	"errors"
 	"fmt"
 	"io"
-	"os"
+	"sort"
+	"strconv"
 	"strings"
 	"unicode"
 
@@ -12,7 +13,22 @@ import (
 	"github.com/spf13/pflag"
 )
 
-var AllowedMultiFormats = []string{"comma", "newline", "space", "json"}
+var AllowedMultiFormats = []string{"comma", "newline", "space", "json", "csv", "nul"}
+
+// exclusiveMultiFormats are the multi formats which cannot be combined with other formats.
+// The "sep:<string>" formats are exclusive as well.
+var exclusiveMultiFormats = []string{"json", "csv", "nul"}
+
+// sepMultiFormatPrefix starts the multi format using an arbitrary separator, e.g. "sep::" for PATH-style values.
+const sepMultiFormatPrefix = "sep:"
+
+func isSepMultiFormat(format string) bool {
+	return strings.HasPrefix(format, sepMultiFormatPrefix) && len(format) > len(sepMultiFormatPrefix)
+}
+
+func isExclusiveMultiFormat(format string) bool {
+	return checkInStringSlice(format, exclusiveMultiFormats) || isSepMultiFormat(format)
+}
 
 func checkInStringSlice(value string, slice []string) bool {
 	for _, f := range slice {
@@ -25,12 +41,123 @@ func checkInStringSlice(value string, slice []string) bool {
 
 func checkMultiFormat(formats []string, flag string) error {
 	for _, format := range formats {
-		if !checkInStringSlice(format, AllowedMultiFormats) {
-			return fmt.Errorf("invalid multi format: %s for flag %s, allowed formats are: %v", format, flag, AllowedMultiFormats)
+		if !checkInStringSlice(format, AllowedMultiFormats) && !isSepMultiFormat(format) {
+			return fmt.Errorf("invalid multi format: %s for flag %s, allowed formats are: %v or %s<string>", format, flag, AllowedMultiFormats, sepMultiFormatPrefix)
 		}
+		if isExclusiveMultiFormat(format) && len(formats) > 1 {
+			return fmt.Errorf("multi format '%s' for flag %s cannot be combined with other formats", format, flag)
+		}
+	}
+	return nil
+}
+
+// checkNulMultiFormat rejects the nul multi format unless the values are output as powershell variables,
+// the only ones which can hold the NUL characters joining them.
+func checkNulMultiFormat(formats []string, output string, shellType ShellType, flag string) error {
+	if !checkInStringSlice("nul", formats) {
+		return nil
 	}
-	if checkInStringSlice("json", formats) && len(formats) > 1 {
-		return fmt.Errorf("multi format 'json' for flag %s cannot be combined with other formats", flag)
+	if !isShellOutput(output) {
+		return fmt.Errorf("multi format nul for flag %s joins the values with NUL characters, which output %s cannot hold, use %s<string> instead", flag, output, sepMultiFormatPrefix)
+	}
+	if shellType != ShellTypePowershell {
+		return fmt.Errorf("multi format nul for flag %s joins the values with NUL characters, which variables of shell type %s cannot hold, use %s<string> instead", flag, shellType, sepMultiFormatPrefix)
+	}
+	return nil
+}
+
+// negatedFlagName returns the name of the flag registered to switch off the bool flag name.
+func negatedFlagName(name string) string {
+	return "no-" + name
+}
+
+// exportScope maps the deprecated export switch to the scope it meant for the shell type:
+// a plain or exported session variable for sh-like shells unless a persist target is given, the same for csh,
+// and a session or persistent user variable for powershell and cmd. The shell types which only set
+// environment variables always use the env scope.
+func exportScope(shellType ShellType, export bool, shPersist string) Scope {
+	if isEnvOnlyShellType(shellType) {
+		return ScopeEnv
+	}
+	if !export {
+		if shellType == ShellTypeSh || shellType == ShellTypeCsh {
+			return ScopeShell
+		}
+		return ScopeEnv
+	}
+	if shellType == ShellTypeCsh || shellType == ShellTypeSh && (shPersist == "" || shPersist == "none") {
+		return ScopeEnv
+	}
+	return ScopeUserPersistent
+}
+
+// resolveScope returns the scope given by the scope flag, or derived from the export flag when it is omitted.
+func resolveScope(cmd *cobra.Command, scopeFlag string, exportFlag string, shellType ShellType, shPersist string) (Scope, error) {
+	export, err := cmd.Flags().GetBool(exportFlag)
+	if err != nil {
+		return ScopeShell, err
+	}
+	if !cmd.Flags().Changed(scopeFlag) {
+		return exportScope(shellType, export, shPersist), nil
+	}
+	if cmd.Flags().Changed(exportFlag) {
+		return ScopeShell, fmt.Errorf("--%s cannot be combined with --%s", exportFlag, scopeFlag)
+	}
+	scopeValue, err := cmd.Flags().GetString(scopeFlag)
+	if err != nil {
+		return ScopeShell, err
+	}
+	scope, err := ScopeString(scopeValue)
+	if err != nil {
+		return ScopeShell, fmt.Errorf("invalid scope: %s for --%s, allowed scopes are: %v", scopeValue, scopeFlag, ScopeStrings())
+	}
+	if scope == ScopeUserPersistent && shellType == ShellTypeSh && (shPersist == "" || shPersist == "none") {
+		return ScopeShell, fmt.Errorf("scope %s of --%s requires --sh-persist for shell type %s", scope, scopeFlag, shellType)
+	}
+	if scope == ScopeUserPersistent && (isEnvOnlyShellType(shellType) || shellType == ShellTypeCsh) {
+		return ScopeShell, fmt.Errorf("scope %s of --%s is not supported by shell type %s", scope, scopeFlag, shellType)
+	}
+	return scope, nil
+}
+
+// checkFlagType validates the options which are incompatible with the flag type
+// and normalizes the default value to the canonical form of the type.
+func checkFlagType(spec *FlagSpec, flag string, specs map[string]*FlagSpec) error {
+	if spec.Type == FlagTypeStr {
+		return nil
+	}
+	if isPathFlagType(spec.Type) {
+		return checkPathOptions(spec.PathChecks, spec.PathNormalize, flag)
+	}
+	if spec.Multi {
+		return fmt.Errorf("flag %s of type %s cannot be multi-valued", flag, spec.Type)
+	}
+	if len(spec.Choices) > 0 {
+		return fmt.Errorf("flag %s of type %s does not support choices", flag, spec.Type)
+	}
+	if spec.NoOptDefValue != "" {
+		return fmt.Errorf("flag %s of type %s does not support empty value", flag, spec.Type)
+	}
+	switch spec.Type {
+	case FlagTypeBool:
+		if _, exists := specs[negatedFlagName(flag)]; exists {
+			return fmt.Errorf("flag %s conflicts with the negation of bool flag %s", negatedFlagName(flag), flag)
+		}
+		if len(spec.Default) > 0 {
+			b, err := strconv.ParseBool(spec.Default[0])
+			if err != nil {
+				return fmt.Errorf("default value %s for bool flag %s is not a valid boolean", spec.Default[0], flag)
+			}
+			spec.Default = []string{strconv.FormatBool(b)}
+		}
+	case FlagTypeCount:
+		if len(spec.Default) > 0 {
+			n, err := strconv.Atoi(spec.Default[0])
+			if err != nil || n < 0 {
+				return fmt.Errorf("default value %s for count flag %s is not a non-negative integer", spec.Default[0], flag)
+			}
+			spec.Default = []string{strconv.Itoa(n)}
+		}
 	}
 	return nil
 }
@@ -43,19 +170,130 @@ It can prompt users with keyboard-driven selectors, enforce allowed values,
 handle multi-valued flags, and finally emit shell-friendly "export" statements
 so calling scripts can eval/source the output to import variables into their environment.`
 
-// Run is the migrated command logic for the bind command.
-func Run(cmd *cobra.Command, args []string) error {
-	cmd.SilenceErrors = true
-	cmd.SilenceUsage = true
+// ShortNameError reports a short name which the user command cannot register: one which is not a
+// single character, the h of the help flag, or the short name of another flag.
+type ShortNameError struct {
+	Flag string
+	// Code is the lint code of the problem: short-invalid, short-help or short-duplicate
+	Code    string
+	message string
+}
+
+func (e *ShortNameError) Error() string {
+	return fmt.Sprintf("flag %s: %s", e.Flag, e.message)
+}
+
+// checkShortName checks the short name of the flag, shorts maps the short names checked so far to
+// their flags and gets the short name of the flag once it is accepted.
+func checkShortName(short string, flag string, shorts map[string]string) *ShortNameError {
+	if short == "" {
+		return nil
+	}
+	if len(short) != 1 {
+		return &ShortNameError{Flag: flag, Code: "short-invalid", message: fmt.Sprintf("short name %q must be a single ASCII character", short)}
+	}
+	if short == "h" {
+		return &ShortNameError{Flag: flag, Code: "short-help", message: "short name h collides with -h of the help flag, which then no longer shows the help"}
+	}
+	if owner, exists := shorts[short]; exists {
+		return &ShortNameError{Flag: flag, Code: "short-duplicate", message: fmt.Sprintf("short name %s is already used by flag %s", short, owner)}
+	}
+	shorts[short] = flag
+	return nil
+}
+
+// checkShortNames rejects the short names which would make the user command panic or lose -h,
+// the flags are checked in the order of their names.
+func checkShortNames(flags map[string]*FlagSpec) error {
+	var keys []string
+	for k := range flags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	shorts := make(map[string]string)
+	for _, key := range keys {
+		if err := checkShortName(flags[key].ShortName, key, shorts); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Run is the migrated command logic for the bind command, cmd gives the names of the root command in the
+// help of bind. The output, the help and the environment variables are taken from env instead of the process.
+func Run(cmd *cobra.Command, args []string, env *Env) error {
 	var err error
 	cmdArgs, userArgs := splitAtDoubleDash(args)
-	spec, err := collectSpecs(cmd, cmdArgs, userArgs)
+	spec, err := collectSpecs(cmd, cmdArgs, userArgs, env)
 	if err != nil {
 		return err
 	} else if spec == nil {
 		// 仅请求帮助信息，退出成功
 		return nil
 	}
+	realCmd := newUserCommand(spec, userArgs, env, func(cmd *cobra.Command, args []string) error {
+		// explain only reports the resolution, without writing or printing anything eval-able
+		if spec.Explain {
+			return WriteExplain(env.Stderr, spec, env)
+		}
+		output, err := exportEnvVars(spec, env)
+		if err != nil {
+			return err
+		}
+		// the outputs appending to a file print nothing
+		if spec.OutputFile != "" {
+			if err := WriteOutputFile(spec.OutputFile, output); err != nil {
+				return err
+			}
+		} else if _, ok := outputFileEnvs[spec.Output]; !ok {
+			fmt.Fprintln(env.Stdout, output)
+		}
+		if spec.Debug {
+			fmt.Fprintln(env.Stderr, output)
+		}
+		return nil
+	})
+
+	realCmd.SetHelpFunc(func(c *cobra.Command, s []string) {
+		writeCommandHelp(env.Stderr, c)
+		if spec.Explain {
+			return
+		}
+		if shellType, err := decideShellType(spec.ShellType, env); err != nil {
+			fmt.Fprintf(env.Stderr, "Error deciding shell type: %v\n", err)
+		} else {
+			exportLines, err := exportEnvVar(shellType, spec, spec.HelpVar, "true", spec.HelpScope, false)
+			if err != nil {
+				fmt.Fprintf(env.Stderr, "Error generating help env var export: %v\n", err)
+			} else {
+				fmt.Fprint(env.Stdout, strings.Join(exportLines, "\n"))
+			}
+		}
+	})
+
+	realCmd.SetArgs(userArgs[1:]) // skip the first arg which is the command name
+	return realCmd.Execute()
+}
+
+// writeCommandHelp writes the description and the usage of the command built by newUserCommand.
+func writeCommandHelp(w io.Writer, c *cobra.Command) {
+	usage := c.Long
+	if usage == "" {
+		usage = c.Short
+	}
+	usage = strings.TrimRightFunc(usage, unicode.IsSpace)
+	if usage != "" {
+		fmt.Fprintln(w, usage)
+		fmt.Fprintln(w)
+	}
+	if c.Runnable() || c.HasSubCommands() {
+		fmt.Fprint(w, c.UsageString())
+	}
+}
+
+// newUserCommand builds the command parsing the user args by spec, run is called once the values
+// of the flags are resolved into spec. The command writes to env.Stderr.
+func newUserCommand(spec *CmdSpec, userArgs []string, env *Env, run func(cmd *cobra.Command, args []string) error) *cobra.Command {
 	realCmd := &cobra.Command{
 		Use:   spec.Name,
 		Short: spec.ShortDesc,
@@ -91,86 +329,45 @@ func Run(cmd *cobra.Command, args []string) error {
 			}
 		},
 		RunE: func(cmd *cobra.Command, args []string) error {
-			for flagName, spec := range spec.Flags {
-				valueSet := false
-				if spec.Required && !cmd.Flags().Changed(flagName) {
-					if spec.Default == nil {
-						return fmt.Errorf("required flag %s is not provided and has no default value", flagName)
-					} else {
-						spec.Value = spec.Default
-						valueSet = true
-					}
-				}
-				if !valueSet {
-					if spec.Multi {
-						values, err := cmd.Flags().GetStringArray(flagName)
-						if err != nil {
-							return err
-						}
-						if values, err := ParseMultiValues(spec.MultiFormat, values, flagName); err != nil {
-							return err
-						} else {
-							spec.Value = values
-						}
-					} else {
-						value, err := cmd.Flags().GetString(flagName)
-						if err != nil {
-							return err
-						}
-						spec.Value = []string{value}
-					}
-				}
-				if len(spec.Choices) > 0 {
-					if len(spec.Value) == 0 {
-						return fmt.Errorf("value for flag %s is empty but choices are defined %v", flagName, spec.Choices)
-					}
-					for _, val := range spec.Value {
-						if !checkInStringSlice(val, spec.Choices) {
-							return fmt.Errorf("value %s for flag %s is not in allowed choices %v", val, flagName, spec.Choices)
-						}
-					}
-				}
-			}
-			output, err := exportEnvVars(spec)
-			if err != nil {
+			if err := resolveFlagValues(cmd, spec, userArgs, env); err != nil {
 				return err
 			}
-			fmt.Println(output)
-			if spec.Debug {
-				fmt.Fprintln(os.Stderr, output)
-			}
-			return nil
+			return run(cmd, args)
 		},
 	}
+	// cobra prints the usage on errors to its out stream, stdout is left for the output to eval
+	realCmd.SetOut(env.Stderr)
+	realCmd.SetErr(env.Stderr)
 
-	realCmd.SetHelpFunc(func(c *cobra.Command, s []string) {
-		helpOut := os.Stderr
-		helpVarOut := os.Stdout
-		usage := c.Long
-		if usage == "" {
-			usage = c.Short
-		}
-		usage = strings.TrimRightFunc(usage, unicode.IsSpace)
-		if usage != "" {
-			fmt.Fprintln(helpOut, usage)
-			fmt.Fprintln(helpOut)
-		}
-		if c.Runnable() || c.HasSubCommands() {
-			fmt.Fprint(helpOut, c.UsageString())
-		}
-		if shellType, err := decideShellType(spec.ShellType); err != nil {
-			fmt.Fprintf(helpOut, "Error deciding shell type: %v\n", err)
-		} else {
-			exportLine, err := exportEnvVar(shellType, spec.HelpVar, "true", spec.HelpExport)
-			if err != nil {
-				fmt.Fprintf(helpOut, "Error generating help env var export: %v\n", err)
-			} else {
-				fmt.Fprint(helpVarOut, exportLine)
+	for flagName, spec := range spec.Flags {
+		if spec.Type == FlagTypeBool {
+			defaultVar := len(spec.Default) > 0 && spec.Default[0] == "true"
+			realCmd.Flags().BoolP(flagName, spec.ShortName, defaultVar, spec.Helper)
+			realCmd.Flags().Bool(negatedFlagName(flagName), false, fmt.Sprintf("Set --%s to false", flagName))
+			realCmd.MarkFlagsMutuallyExclusive(flagName, negatedFlagName(flagName))
+			continue
+		} else if spec.Type == FlagTypeCount {
+			// count from zero so that the default only applies when the flag is omitted
+			realCmd.Flags().CountP(flagName, spec.ShortName, spec.Helper)
+			if len(spec.Default) > 0 {
+				realCmd.Flags().Lookup(flagName).DefValue = spec.Default[0]
 			}
+			continue
+		} else if spec.Type == FlagTypeMap {
+			realCmd.Flags().StringArrayP(flagName, spec.ShortName, spec.Default, spec.Helper)
+			if len(spec.MapKeys) > 0 {
+				realCmd.RegisterFlagCompletionFunc(flagName, func(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+					var completions []cobra.Completion
+					for _, key := range spec.MapKeys {
+						if strings.HasPrefix(key+"=", toComplete) {
+							completions = append(completions, key+"=")
+						}
+					}
+					return completions, cobra.ShellCompDirectiveNoSpace
+				})
+			}
+			continue
 		}
-	})
-
-	for flagName, spec := range spec.Flags {
 		if !spec.Multi {
 			var defaultVar string
 			if len(spec.Default) > 0 {
@@ -195,13 +392,123 @@ func Run(cmd *cobra.Command, args []string) error {
 				}
 				return completions, cobra.ShellCompDirectiveDefault
 			})
+		} else if spec.Type == FlagTypeDir {
+			realCmd.MarkFlagDirname(flagName)
+		} else if spec.Type == FlagTypeFile || spec.Type == FlagTypePath {
+			realCmd.MarkFlagFilename(flagName)
 		}
 		// if spec.Required {
 		// 	realCmd.MarkFlagRequired(flagName)
 		// }
 	}
-	realCmd.SetArgs(userArgs[1:]) // skip the first arg which is the command name
-	return realCmd.Execute()
+	return realCmd
+}
+
+// resolveFlagValues sets the value, source and missing state of every flag of spec from the parsed user args.
+func resolveFlagValues(cmd *cobra.Command, spec *CmdSpec, userArgs []string, env *Env) error {
+	sources := recordValueSources(cmd.Flags(), userArgs[1:])
+	for flagName, spec := range spec.Flags {
+		valueSet := false
+		changed := cmd.Flags().Changed(flagName)
+		if spec.Type == FlagTypeBool {
+			changed = changed || cmd.Flags().Changed(negatedFlagName(flagName))
+		}
+		// a flag has no value when it is omitted without a default, bool and count flags always have one
+		spec.Missing = !changed && spec.Default == nil && spec.Type != FlagTypeBool && spec.Type != FlagTypeCount
+		spec.Source = valueSource(spec, flagName, changed, sources)
+		if spec.Required && !changed {
+			if spec.Default == nil {
+				return fmt.Errorf("required flag %s is not provided and has no default value", flagName)
+			} else {
+				spec.Value = spec.Default
+				valueSet = true
+			}
+		}
+		if !valueSet {
+			if spec.Type == FlagTypeBool {
+				value, err := cmd.Flags().GetBool(flagName)
+				if err != nil {
+					return err
+				}
+				if cmd.Flags().Changed(negatedFlagName(flagName)) {
+					value = false
+				}
+				spec.Value = []string{strconv.FormatBool(value)}
+			} else if spec.Type == FlagTypeCount {
+				if !changed && len(spec.Default) > 0 {
+					spec.Value = spec.Default
+				} else {
+					value, err := cmd.Flags().GetCount(flagName)
+					if err != nil {
+						return err
+					}
+					spec.Value = []string{strconv.Itoa(value)}
+				}
+			} else if spec.Type == FlagTypeMap {
+				values, err := cmd.Flags().GetStringArray(flagName)
+				if err != nil {
+					return err
+				}
+				if values, err := ParseMapValues(spec.MapDuplicate, spec.MapKeys, values, flagName); err != nil {
+					return err
+				} else {
+					spec.Value = values
+				}
+			} else if spec.Multi && !changed {
+				// the default was parsed by its multi format when the spec was built, parsing it again
+				// would split the items of csv, nul and sep defaults once more
+				spec.Value = spec.Default
+			} else if spec.Multi {
+				values, err := cmd.Flags().GetStringArray(flagName)
+				if err != nil {
+					return err
+				}
+				if values, err := ParseMultiValues(spec.MultiFormat, spec.MultiPreserve, values, flagName); err != nil {
+					return err
+				} else {
+					spec.Value = values
+				}
+			} else {
+				value, err := cmd.Flags().GetString(flagName)
+				if err != nil {
+					return err
+				}
+				spec.Value = []string{value}
+			}
+		}
+		if len(spec.Choices) > 0 {
+			if len(spec.Value) == 0 {
+				return fmt.Errorf("value for flag %s is empty but choices are defined %v", flagName, spec.Choices)
+			}
+			for _, val := range spec.Value {
+				if !checkInStringSlice(val, spec.Choices) {
+					return fmt.Errorf("value %s for flag %s is not in allowed choices %v", val, flagName, spec.Choices)
+				}
+			}
+		}
+		if isPathFlagType(spec.Type) {
+			paths := make([]string, len(spec.Value))
+			for i, val := range spec.Value {
+				path, err := NormalizePath(val, spec.PathNormalize, flagName, env)
+				if err != nil {
+					return err
+				}
+				if err := CheckPath(spec.Type, path, spec.PathChecks, flagName, env); err != nil {
+					return err
+				}
+				paths[i] = path
+			}
+			spec.Value = paths
+		}
+		if spec.Multi {
+			if values, err := CanonicalizeMultiValues(spec.Unique, spec.Sort, spec.MaxItems, spec.Value, flagName); err != nil {
+				return err
+			} else {
+				spec.Value = values
+			}
+		}
+	}
+	return nil
 }
 
 func collectFlagsName(args []string) ([]string, error) {
@@ -247,6 +554,8 @@ func collectFlagsMulti(specs map[string]*FlagSpec, flagsName []string, argsValue
 		fs.BoolP(flag_name, "", false, "")
 		flag_name = fmt.Sprintf("flag-%s-multi-format", flagName)
 		fs.StringSliceP(flag_name, "", AllowedMultiFormats[0:1], "")
+		flag_name = fmt.Sprintf("flag-%s-type", flagName)
+		fs.StringP(flag_name, "", FlagTypeStr.String(), "")
 	}
 	fs.Parse(argsValues)
 	for _, flagName := range flagsName {
@@ -262,25 +571,41 @@ func collectFlagsMulti(specs map[string]*FlagSpec, flagsName []string, argsValue
 			return err
 		}
 		specs[flagName].MultiFormat = multiFormat
+		flag_name = fmt.Sprintf("flag-%s-type", flagName)
+		typeValue, err := fs.GetString(flag_name)
+		if err != nil {
+			return err
+		}
+		// invalid types are reported later by the bind command
+		if flagType, err := FlagTypeString(typeValue); err == nil {
+			specs[flagName].Type = flagType
+		}
 	}
 	return nil
 }
 
-func collectSpecs(cmd *cobra.Command, bindArgs []string, userArgs []string) (*CmdSpec, error) {
+// collectSpecs builds the spec from the bind options, cmd gives the names of the root command in the help of
+// bind. The help and the errors are written to env, which also decides the shell type when it is auto.
+func collectSpecs(cmd *cobra.Command, bindArgs []string, userArgs []string, env *Env) (*CmdSpec, error) {
 	rootCmd := cmd.Root()
 	specs := &CmdSpec{
 		Flags:       make(map[string]*FlagSpec),
 		ArgsChoices: [][]string{},
 		ArgsValue:   []string{},
 	}
+	bindArgs, err := ExpandSpecArgs(bindArgs)
+	if err != nil {
+		fmt.Fprintln(env.Stderr, cmd.ErrPrefix(), err)
+		return nil, err
+	}
 	flagsName, err := collectFlagsName(bindArgs)
 	if err != nil {
-		cmd.PrintErrln(fmt.Sprintf("%s %v", cmd.ErrPrefix(), err))
+		fmt.Fprintln(env.Stderr, cmd.ErrPrefix(), err)
 		return nil, err
 	}
 	err = collectFlagsMulti(specs.Flags, flagsName, bindArgs)
 	if err != nil {
-		cmd.PrintErrln(fmt.Sprintf("%s %v", cmd.ErrPrefix(), err))
+		fmt.Fprintln(env.Stderr, cmd.ErrPrefix(), err)
 		return nil, err
 	}
 	virtualRootCmd := &cobra.Command{
@@ -289,6 +614,8 @@ func collectSpecs(cmd *cobra.Command, bindArgs []string, userArgs []string) (*Cm
 		Long:  rootCmd.Long,
 		Run:   func(cmd *cobra.Command, args []string) {},
 	}
+	virtualRootCmd.SetOut(env.Stdout)
+	virtualRootCmd.SetErr(env.Stderr)
 
 	example := `  [---in shell script: my-shell.sh---]
   %s bind \
@@ -346,6 +673,11 @@ func collectSpecs(cmd *cobra.Command, bindArgs []string, userArgs []string) (*Cm
 				return err
 			}
 			specs.Debug = debug
+			explain, err := cmd.Flags().GetBool("explain")
+			if err != nil {
+				return err
+			}
+			specs.Explain = explain
 			shellType, err := cmd.Flags().GetString("shell-type")
 			if err != nil {
 				return err
@@ -371,16 +703,107 @@ func collectSpecs(cmd *cobra.Command, bindArgs []string, userArgs []string) (*Cm
 				return err
 			}
 			specs.HelpVar = helpVar
-			helpExport, err := cmd.Flags().GetBool("help-export")
+			cmdScript, err := cmd.Flags().GetString("cmd-script")
+			if err != nil {
+				return err
+			}
+			if !checkInStringSlice(cmdScript, AllowedCmdScripts) {
+				return fmt.Errorf("invalid cmd script mode: %s, allowed modes are: %v", cmdScript, AllowedCmdScripts)
+			}
+			specs.CmdScript = cmdScript
+			cmdDelayedExpansion, err := cmd.Flags().GetBool("cmd-delayed-expansion")
+			if err != nil {
+				return err
+			}
+			specs.CmdDelayedExpansion = cmdDelayedExpansion
+			unsetMissing, err := cmd.Flags().GetBool("unset-missing")
+			if err != nil {
+				return err
+			}
+			specs.UnsetMissing = unsetMissing
+			shDeclare, err := cmd.Flags().GetString("sh-declare")
+			if err != nil {
+				return err
+			}
+			if !checkInStringSlice(shDeclare, AllowedShDeclares) {
+				return fmt.Errorf("invalid sh declare mode: %s, allowed modes are: %v", shDeclare, AllowedShDeclares)
+			}
+			specs.ShDeclare = shDeclare
+			shPersist, err := cmd.Flags().GetString("sh-persist")
+			if err != nil {
+				return err
+			}
+			if !checkInStringSlice(shPersist, AllowedShPersists) {
+				return fmt.Errorf("invalid sh persist target: %s, allowed targets are: %v", shPersist, AllowedShPersists)
+			}
+			specs.ShPersist = shPersist
+			output, err := cmd.Flags().GetString("output")
+			if err != nil {
+				return err
+			}
+			if !checkInStringSlice(output, AllowedOutputs) {
+				return fmt.Errorf("invalid output: %s, allowed outputs are: %v", output, AllowedOutputs)
+			}
+			specs.Output = output
+			outputFile, err := cmd.Flags().GetString("output-file")
+			if err != nil {
+				return err
+			}
+			if _, ok := outputFileEnvs[output]; ok && outputFile != "" {
+				return fmt.Errorf("--output-file cannot be used with output %s, which appends to $%s", output, outputFileEnvs[output])
+			}
+			specs.OutputFile = outputFile
+			decidedShellType, err := decideShellType(specs.ShellType, env)
 			if err != nil {
 				return err
 			}
-			specs.HelpExport = helpExport
+			if helpScope, err := resolveScope(cmd, "help-scope", "help-export", decidedShellType, specs.ShPersist); err != nil {
+				return err
+			} else {
+				specs.HelpScope = helpScope
+			}
 			for flagName, spec := range specs.Flags {
 				err = checkMultiFormat(spec.MultiFormat, flagName)
 				if err != nil {
 					return err
 				}
+				if spec.Multi || spec.Type == FlagTypeMap {
+					if err := checkNulMultiFormat(spec.MultiFormat, specs.Output, decidedShellType, flagName); err != nil {
+						return err
+					}
+				}
+				preserveValue, err := cmd.Flags().GetBool(fmt.Sprintf("flag-%s-multi-preserve", flagName))
+				if err != nil {
+					return err
+				}
+				spec.MultiPreserve = preserveValue
+				uniqueValue, err := cmd.Flags().GetString(fmt.Sprintf("flag-%s-unique", flagName))
+				if err != nil {
+					return err
+				}
+				spec.Unique = uniqueValue
+				sortValue, err := cmd.Flags().GetString(fmt.Sprintf("flag-%s-sort", flagName))
+				if err != nil {
+					return err
+				}
+				spec.Sort = sortValue
+				maxItemsValue, err := cmd.Flags().GetInt(fmt.Sprintf("flag-%s-max-items", flagName))
+				if err != nil {
+					return err
+				}
+				spec.MaxItems = maxItemsValue
+				err = checkMultiPolicy(spec.Unique, spec.Sort, spec.MaxItems, flagName)
+				if err != nil {
+					return err
+				}
+				if !spec.Multi {
+					// the policies are only applied to multi-valued flags, so they would be silently ignored
+					for _, option := range []string{"unique", "sort", "max-items"} {
+						if cmd.Flags().Changed(fmt.Sprintf("flag-%s-%s", flagName, option)) {
+							return fmt.Errorf("--flag-%s-%s requires flag %s to be multi-valued, add --flag-%s-multi", flagName, option, flagName, flagName)
+						}
+					}
+				}
 				shortFlag := fmt.Sprintf("flag-%s-short", flagName)
 				defaultFlag := fmt.Sprintf("flag-%s-default", flagName)
 				emptyValueFlag := fmt.Sprintf("flag-%s-empty-value", flagName)
@@ -389,6 +812,44 @@ func collectSpecs(cmd *cobra.Command, bindArgs []string, userArgs []string) (*Cm
 				helperFlag := fmt.Sprintf("flag-%s-helper", flagName)
 				envFlag := fmt.Sprintf("flag-%s-env-name", flagName)
 				exportFlag := fmt.Sprintf("flag-%s-export", flagName)
+				typeFlag := fmt.Sprintf("flag-%s-type", flagName)
+				typeValue, err := cmd.Flags().GetString(typeFlag)
+				if err != nil {
+					return err
+				}
+				if flagType, err := FlagTypeString(typeValue); err != nil {
+					return fmt.Errorf("invalid type: %s for flag %s, allowed types are: %v", typeValue, flagName, FlagTypeStrings())
+				} else {
+					spec.Type = flagType
+				}
+				mapDuplicate, err := cmd.Flags().GetString(fmt.Sprintf("flag-%s-map-duplicate", flagName))
+				if err != nil {
+					return err
+				}
+				spec.MapDuplicate = mapDuplicate
+				mapKeys, err := cmd.Flags().GetStringSlice(fmt.Sprintf("flag-%s-map-keys", flagName))
+				if err != nil {
+					return err
+				}
+				spec.MapKeys = mapKeys
+				mapOutput, err := cmd.Flags().GetString(fmt.Sprintf("flag-%s-map-output", flagName))
+				if err != nil {
+					return err
+				}
+				spec.MapOutput = mapOutput
+				if err := checkMapOptions(spec.MapDuplicate, spec.MapOutput, flagName); err != nil {
+					return err
+				}
+				pathChecks, err := cmd.Flags().GetStringSlice(fmt.Sprintf("flag-%s-path-checks", flagName))
+				if err != nil {
+					return err
+				}
+				spec.PathChecks = pathChecks
+				pathNormalize, err := cmd.Flags().GetStringSlice(fmt.Sprintf("flag-%s-path-normalize", flagName))
+				if err != nil {
+					return err
+				}
+				spec.PathNormalize = pathNormalize
 				shortValue, err := cmd.Flags().GetString(shortFlag)
 				if err != nil {
 					return err
@@ -404,17 +865,39 @@ func collectSpecs(cmd *cobra.Command, bindArgs []string, userArgs []string) (*Cm
 					return err
 				}
 				spec.EnvName = envValue
-				exportValue, err := cmd.Flags().GetBool(exportFlag)
+				if scope, err := resolveScope(cmd, fmt.Sprintf("flag-%s-scope", flagName), exportFlag, decidedShellType, specs.ShPersist); err != nil {
+					return err
+				} else {
+					spec.Scope = scope
+				}
+				unsetMissingValue, err := cmd.Flags().GetBool(fmt.Sprintf("flag-%s-unset-missing", flagName))
+				if err != nil {
+					return err
+				}
+				spec.UnsetMissing = unsetMissingValue
+				readonlyValue, err := cmd.Flags().GetBool(fmt.Sprintf("flag-%s-readonly", flagName))
 				if err != nil {
 					return err
 				}
-				spec.Export = exportValue
+				spec.Readonly = readonlyValue
 				if cmd.Flags().Changed(defaultFlag) {
-					if spec.Multi {
+					if spec.Type == FlagTypeMap {
 						if defaultValues, err := cmd.Flags().GetStringArray(defaultFlag); err != nil {
 							return err
 						} else {
-							if defaultValues, err := ParseMultiValues(spec.MultiFormat, defaultValues, flagName); err != nil {
+							if defaultValues, err := ParseMapValues(spec.MapDuplicate, spec.MapKeys, defaultValues, flagName); err != nil {
+								return err
+							} else {
+								spec.Default = defaultValues
+							}
+						}
+					} else if spec.Multi {
+						if defaultValues, err := cmd.Flags().GetStringArray(defaultFlag); err != nil {
+							return err
+						} else {
+							if defaultValues, err := ParseMultiValues(spec.MultiFormat, spec.MultiPreserve, defaultValues, flagName); err != nil {
+								return err
+							} else if defaultValues, err := CanonicalizeMultiValues(spec.Unique, spec.Sort, spec.MaxItems, defaultValues, flagName); err != nil {
 								return err
 							} else {
 								spec.Default = defaultValues
@@ -438,8 +921,10 @@ func collectSpecs(cmd *cobra.Command, bindArgs []string, userArgs []string) (*Cm
 				if choicesValue, err := cmd.Flags().GetStringArray(choicesFlag); err != nil {
 					return err
 				} else {
-					if choicesValue, err := ParseMultiValues(spec.MultiFormat, choicesValue, flagName); err != nil {
+					if choicesValue, err := ParseMultiValues(spec.MultiFormat, spec.MultiPreserve, choicesValue, flagName); err != nil {
 						return err
+					} else if choicesValue, err := UniqueMultiValues(spec.Unique, choicesValue, flagName); err != nil {
+						return fmt.Errorf("invalid choices: %w", err)
 					} else {
 						spec.Choices = choicesValue
 					}
@@ -450,6 +935,9 @@ func collectSpecs(cmd *cobra.Command, bindArgs []string, userArgs []string) (*Cm
 				}
 				spec.Required = requiredValue
 
+				if err := checkFlagType(spec, flagName, specs.Flags); err != nil {
+					return err
+				}
 				if len(spec.Choices) > 0 && spec.Default != nil {
 					if len(spec.Default) == 0 && !spec.Required {
 						return fmt.Errorf("default value for optional flag %s is empty but choices are defined %v", flagName, spec.Choices)
@@ -461,66 +949,30 @@ func collectSpecs(cmd *cobra.Command, bindArgs []string, userArgs []string) (*Cm
 					}
 				}
 			}
+			if err := checkShortNames(specs.Flags); err != nil {
+				return err
+			}
+			envNameSanitize, err := cmd.Flags().GetString("env-name-sanitize")
+			if err != nil {
+				return err
+			}
+			if err := ResolveEnvNames(decidedShellType, specs, envNameSanitize); err != nil {
+				return err
+			}
 			return nil
 		},
 	}
-	bindCmd.Flags().StringP("name", "n", "", "The name of the command")
-	bindCmd.Flags().StringP("short", "s", "", "The short description of the command")
-	bindCmd.Flags().StringP("long", "l", "", "The long description of the command")
-	// bindCmd.Flags().BoolP("interactive", "i", false, "Enable interactive mode for user prompts")
-	bindCmd.Flags().StringP("env-prefix", "e", "", "The environment variable prefix for the command; all output env vars will be prefixed with it, except those whose names are specified using --flag-<name>-env-name")
-	bindCmd.Flags().BoolP("allow-repeated-flags", "r", false, "Allow repeated flag names")
-	bindCmd.Flags().BoolP("debug", "d", false, "Enable debug mode, print output to stderr as well")
-	bindCmd.Flags().StringP("shell-type", "", ShellTypeAuto.String(), fmt.Sprintf("The shell type for output, allowed values: %s", strings.Join(ShellTypeStrings(), ", ")))
-	bindCmd.Flags().StringP("args-range", "a", "", "The range of positional arguments, e.g. 1, >1, <=3, [1,3], (,5], [2,), (,) for unlimited")
-	bindCmd.Flags().StringP("help-var", "", "IS_HELP", "The environment variable name to indicate help request, not effected by --env-prefix")
-	bindCmd.Flags().BoolP("help-export", "", false, "Whether the help environment variable should be exported")
-	bindCmd.Flags().StringSliceP("flag", "f", []string{}, "Name For flag")
-	for flagName, spec := range specs.Flags {
-		shortFlag := fmt.Sprintf("flag-%s-short", flagName)
-		bindCmd.Flags().StringP(shortFlag, "", "", fmt.Sprintf("Short name for flag %s", flagName))
-		helpFlag := fmt.Sprintf("flag-%s-helper", flagName)
-		bindCmd.Flags().StringP(helpFlag, "", "", fmt.Sprintf("Helper text for flag %s", flagName))
-		multiFlag := fmt.Sprintf("flag-%s-multi", flagName)
-		bindCmd.Flags().BoolP(multiFlag, "", false, fmt.Sprintf("Whether flag %s is multi-valued", flagName))
-		multiFormatFlag := fmt.Sprintf("flag-%s-multi-format", flagName)
-		bindCmd.Flags().StringP(
-			multiFormatFlag, "", AllowedMultiFormats[0],
-			fmt.Sprintf("Multi value format for flag %s, allowed value are combined of %v or %v", flagName, strings.Join(AllowedMultiFormats[0:3], ", "), AllowedMultiFormats[3]),
-		)
-		defaultFlag := fmt.Sprintf("flag-%s-default", flagName)
-		if spec.Multi {
-			bindCmd.Flags().StringArrayP(defaultFlag, "", []string{}, fmt.Sprintf(
-				"Default values for flag %s. Note: defaults apply only when the flag is omitted; if the flag is present but given no value (e.g. '--%s'), an empty value is used instead of the default.",
-				flagName, flagName,
-			))
-		} else {
-			bindCmd.Flags().StringP(defaultFlag, "", "", fmt.Sprintf(
-				"Default value for flag %s. Note: default apply only when the flag is omitted; if the flag is present but given no value (e.g. '--%s'), an empty value is used instead of the default.",
-				flagName, flagName,
-			))
-		}
-		emptyValueFlag := fmt.Sprintf("flag-%s-empty-value", flagName)
-		bindCmd.Flags().StringP(emptyValueFlag, "", "", fmt.Sprintf(
-			"The value to use when flag %s is present but given no explicit value (e.g. '--%s'). "+
-				"Note: this applies only when the flag is provided without a value; it does not act as the default when the flag is omitted.",
-			flagName, flagName,
-		))
-		choicesFlag := fmt.Sprintf("flag-%s-choices", flagName)
-		bindCmd.Flags().StringArrayP(choicesFlag, "", []string{}, fmt.Sprintf("Allowed choices for flag %s", flagName))
-		requiredFlag := fmt.Sprintf("flag-%s-required", flagName)
-		bindCmd.Flags().BoolP(requiredFlag, "", false, fmt.Sprintf("Whether flag %s is required", flagName))
-		envFlag := fmt.Sprintf("flag-%s-env-name", flagName)
-		bindCmd.Flags().StringP(envFlag, "", "", fmt.Sprintf("Environment variable name for flag %s, default is upper-case with '-' replaced by '_', not effected by --env-prefix", flagName))
-		exportFlag := fmt.Sprintf("flag-%s-export", flagName)
-		bindCmd.Flags().BoolP(exportFlag, "", false, fmt.Sprintf("Whether flag %s should be exported as environment variable", flagName))
-	}
+	addBindFlags(bindCmd.Flags(), specs.Flags)
 	virtualRootCmd.AddCommand(bindCmd)
 	argsWithBind := append([]string{"bind"}, bindArgs...)
 	virtualRootCmd.SetArgs(argsWithBind)
 
+	// the help of bind goes to stdout, but cobra would print the usage on errors there as well,
+	// where the callers eval it, so it is printed to stderr here
+	virtualRootCmd.SilenceUsage = true
 	err = virtualRootCmd.Execute()
 	if err != nil {
+		fmt.Fprintln(env.Stderr, bindCmd.UsageString())
 		return nil, err
 	}
 	// 如果只请求帮助信息，则退出成功
@@ -531,7 +983,7 @@ func collectSpecs(cmd *cobra.Command, bindArgs []string, userArgs []string) (*Cm
 	if len(userArgs) == 0 {
 		err := errors.New("no user arguments provided after '--', at least $0 should be provided")
 		bindCmd.PrintErrln(fmt.Sprintf("%s %v", bindCmd.ErrPrefix(), err))
-		bindCmd.Usage()
+		fmt.Fprint(env.Stderr, bindCmd.UsageString())
 		return nil, err
 	}
 	if specs.Name == "" {
@@ -557,3 +1009,155 @@ func splitAtDoubleDash(args []string) (before []string, after []string) {
 	after = []string{}
 	return
 }
+
+// addBindFlags registers the options of the bind command, the per-flag options are registered
+// for every flag of specs, whose multi and type settings decide the kind of the default option.
+func addBindFlags(flags *pflag.FlagSet, specs map[string]*FlagSpec) {
+	flags.StringP("name", "n", "", "The name of the command")
+	flags.StringP("short", "s", "", "The short description of the command")
+	flags.StringP("long", "l", "", "The long description of the command")
+	// flags.BoolP("interactive", "i", false, "Enable interactive mode for user prompts")
+	flags.StringP("env-prefix", "e", "", "The environment variable prefix for the command; all output env vars will be prefixed with it, except those whose names are specified using --flag-<name>-env-name")
+	flags.BoolP("allow-repeated-flags", "r", false, "Allow repeated flag names")
+	flags.BoolP("debug", "d", false, "Enable debug mode, print output to stderr as well")
+	flags.BoolP("explain", "", false, "Print the shell type and a table of each flag's variable, final value and source (cli, empty-value, default or missing) to stderr, instead of the output")
+	flags.StringP("shell-type", "", ShellTypeAuto.String(), fmt.Sprintf("The shell type for output, auto uses $%s or detects the shell from the parent processes (see 'argonaut detect-shell'), allowed values: %s", ShellEnvName, strings.Join(ShellTypeStrings(), ", ")))
+	flags.StringP("env-name-sanitize", "", AllowedEnvNameSanitizes[0], fmt.Sprintf(
+		"How to handle environment variable names which are invalid for the shell type: "+
+			"error, or replace the invalid characters with '_' and prefix a leading digit with '_', allowed values: %s",
+		strings.Join(AllowedEnvNameSanitizes, ", "),
+	))
+	flags.StringP("output", "o", AllowedOutputs[0], fmt.Sprintf(
+		"The output target: statements for --shell-type (shell), NAME=value lines appended to the file in $GITHUB_ENV (github-env) or $GITHUB_OUTPUT (github-output), "+
+			"using heredoc delimiters for multi-line values, a GitLab dotenv report (gitlab-dotenv), a quoted .env file (dotenv), a docker --env-file (docker-env) "+
+			"or a systemd EnvironmentFile (systemd-env) printed to stdout; scopes do not apply to the other targets, allowed values: %s",
+		strings.Join(AllowedOutputs, ", "),
+	))
+	flags.StringP("output-file", "", "", "Write the output to this file instead of stdout, the file is replaced, a new file is only readable by the user")
+	flags.StringP("args-range", "a", "", "The range of positional arguments, e.g. 1, >1, <=3, [1,3], (,5], [2,), (,) for unlimited")
+	flags.StringP("help-var", "", "IS_HELP", "The environment variable name to indicate help request, not effected by --env-prefix")
+	flags.BoolP("help-export", "", false, "Deprecated, use --help-scope. Whether the help environment variable should be exported")
+	flags.StringP("help-scope", "", "", fmt.Sprintf(
+		"The scope of the help environment variable, default is shell for sh-like shells and csh and env otherwise, allowed values: %s",
+		strings.Join(ScopeStrings(), ", "),
+	))
+	flags.StringP("cmd-script", "", AllowedCmdScripts[0], fmt.Sprintf(
+		"For shell type cmd, when to write the statements to a temp .cmd script and output a single 'call' line instead: "+
+			"auto (only when a value contains line breaks), always or never, allowed values: %s",
+		strings.Join(AllowedCmdScripts, ", "),
+	))
+	flags.BoolP("cmd-delayed-expansion", "", false, "For shell type cmd, whether the output is executed with delayed expansion enabled, so '!' is escaped")
+	flags.BoolP("unset-missing", "", false, "Unset the environment variables of all flags which are omitted and have no default, so values of a previous run in the same shell do not leak")
+	flags.StringP("sh-declare", "", AllowedShDeclares[0], fmt.Sprintf(
+		"For sh-like shell types, how variables are declared: plain assignment, or function-local with 'local' (bash, zsh, dash), "+
+			"'typeset' (ksh) or 'declare' (bash, zsh), allowed values: %s",
+		strings.Join(AllowedShDeclares, ", "),
+	))
+	flags.StringP("sh-persist", "", AllowedShPersists[0], fmt.Sprintf(
+		"For sh-like shell types, persist the flags of scope user-persistent in a block managed per --name in ~/.profile, ~/.bashrc, ~/.zshenv "+
+			"or ~/.config/environment.d/%s, besides exporting them in the session; remove the block with 'argonaut unpersist', allowed values: %s",
+		environmentDFileName, strings.Join(AllowedShPersists, ", "),
+	))
+	flags.StringArrayP("spec", "", []string{}, "Read options from a yaml or json spec file (json if the extension is .json, see 'argonaut spec export'), the options given after it override the ones of the file")
+	flags.StringSliceP("flag", "f", []string{}, "Name For flag")
+	for flagName, spec := range specs {
+		shortFlag := fmt.Sprintf("flag-%s-short", flagName)
+		flags.StringP(shortFlag, "", "", fmt.Sprintf("Short name for flag %s", flagName))
+		helpFlag := fmt.Sprintf("flag-%s-helper", flagName)
+		flags.StringP(helpFlag, "", "", fmt.Sprintf("Helper text for flag %s", flagName))
+		multiFlag := fmt.Sprintf("flag-%s-multi", flagName)
+		flags.BoolP(multiFlag, "", false, fmt.Sprintf("Whether flag %s is multi-valued", flagName))
+		multiFormatFlag := fmt.Sprintf("flag-%s-multi-format", flagName)
+		flags.StringP(
+			multiFormatFlag, "", AllowedMultiFormats[0],
+			fmt.Sprintf(
+				"Multi value format for flag %s, allowed value are combined of %v or one of %v, %s<string>. "+
+					"A separator containing a comma must be quoted, e.g. '\"%s,\"'. nul values can only be output for powershell",
+				flagName, strings.Join(AllowedMultiFormats[0:3], ", "), strings.Join(exclusiveMultiFormats, ", "), sepMultiFormatPrefix, sepMultiFormatPrefix,
+			),
+		)
+		multiPreserveFlag := fmt.Sprintf("flag-%s-multi-preserve", flagName)
+		flags.BoolP(multiPreserveFlag, "", false, fmt.Sprintf(
+			"Whether empty items and surrounding whitespace are preserved when splitting values of flag %s by comma, newline or space; csv always preserves them",
+			flagName,
+		))
+		uniqueFlag := fmt.Sprintf("flag-%s-unique", flagName)
+		flags.StringP(uniqueFlag, "", AllowedUniquePolicies[0], fmt.Sprintf(
+			"Policy for duplicate values of multi-valued flag %s, its defaults and choices, allowed values: %s",
+			flagName, strings.Join(AllowedUniquePolicies, ", "),
+		))
+		sortFlag := fmt.Sprintf("flag-%s-sort", flagName)
+		flags.StringP(sortFlag, "", AllowedSortOrders[0], fmt.Sprintf(
+			"Sort order for values of multi-valued flag %s, applied after the unique policy, allowed values: %s",
+			flagName, strings.Join(AllowedSortOrders, ", "),
+		))
+		maxItemsFlag := fmt.Sprintf("flag-%s-max-items", flagName)
+		flags.IntP(maxItemsFlag, "", 0, fmt.Sprintf("Maximum number of values for multi-valued flag %s after the unique policy, 0 means unlimited", flagName))
+		defaultFlag := fmt.Sprintf("flag-%s-default", flagName)
+		if spec.Multi || spec.Type == FlagTypeMap {
+			flags.StringArrayP(defaultFlag, "", []string{}, fmt.Sprintf(
+				"Default values for flag %s. Note: defaults apply only when the flag is omitted; if the flag is present but given no value (e.g. '--%s'), an empty value is used instead of the default.",
+				flagName, flagName,
+			))
+		} else {
+			flags.StringP(defaultFlag, "", "", fmt.Sprintf(
+				"Default value for flag %s. Note: default apply only when the flag is omitted; if the flag is present but given no value (e.g. '--%s'), an empty value is used instead of the default.",
+				flagName, flagName,
+			))
+		}
+		emptyValueFlag := fmt.Sprintf("flag-%s-empty-value", flagName)
+		flags.StringP(emptyValueFlag, "", "", fmt.Sprintf(
+			"The value to use when flag %s is present but given no explicit value (e.g. '--%s'). "+
+				"Note: this applies only when the flag is provided without a value; it does not act as the default when the flag is omitted.",
+			flagName, flagName,
+		))
+		choicesFlag := fmt.Sprintf("flag-%s-choices", flagName)
+		flags.StringArrayP(choicesFlag, "", []string{}, fmt.Sprintf("Allowed choices for flag %s", flagName))
+		requiredFlag := fmt.Sprintf("flag-%s-required", flagName)
+		flags.BoolP(requiredFlag, "", false, fmt.Sprintf("Whether flag %s is required", flagName))
+		envFlag := fmt.Sprintf("flag-%s-env-name", flagName)
+		flags.StringP(envFlag, "", "", fmt.Sprintf("Environment variable name for flag %s, default is upper-case with '-' replaced by '_', not effected by --env-prefix", flagName))
+		exportFlag := fmt.Sprintf("flag-%s-export", flagName)
+		flags.BoolP(exportFlag, "", false, fmt.Sprintf(
+			"Deprecated, use --flag-%s-scope. Whether flag %s should be exported: scope env for sh-like shells (user-persistent with --sh-persist) and csh, user-persistent for powershell and cmd",
+			flagName, flagName,
+		))
+		scopeFlag := fmt.Sprintf("flag-%s-scope", flagName)
+		flags.StringP(scopeFlag, "", "", fmt.Sprintf(
+			"The scope of the variable of flag %s: shell (not inherited by child processes; cmd, nushell, elvish and xonsh use env), "+
+				"env (inherited by child processes) or user-persistent (env, and persisted for the user; requires --sh-persist for sh-like shells, "+
+				"not supported by csh, nushell, elvish and xonsh), default is shell for sh-like shells and csh and env otherwise, allowed values: %s",
+			flagName, strings.Join(ScopeStrings(), ", "),
+		))
+		unsetMissingFlag := fmt.Sprintf("flag-%s-unset-missing", flagName)
+		flags.BoolP(unsetMissingFlag, "", false, fmt.Sprintf("Unset the environment variable of flag %s when it is omitted and has no default", flagName))
+		readonlyFlag := fmt.Sprintf("flag-%s-readonly", flagName)
+		flags.BoolP(readonlyFlag, "", false, fmt.Sprintf("For sh-like shell types, whether the variable of flag %s is declared readonly, ignored by other shell types", flagName))
+		typeFlag := fmt.Sprintf("flag-%s-type", flagName)
+		flags.StringP(typeFlag, "", FlagTypeStr.String(), fmt.Sprintf(
+			"Value type for flag %s, allowed values: %s. A bool flag also registers '--no-%s' and outputs true/false, a count flag outputs how many times it is given (e.g. '-vvv' is 3), a map flag accepts repeated key=value inputs, a file, dir or path flag is checked by --flag-%s-path-checks",
+			flagName, strings.Join(FlagTypeStrings(), ", "), flagName, flagName,
+		))
+		mapDuplicateFlag := fmt.Sprintf("flag-%s-map-duplicate", flagName)
+		flags.StringP(mapDuplicateFlag, "", AllowedMapDuplicates[0], fmt.Sprintf(
+			"Policy for repeated keys of map flag %s, allowed values: %s", flagName, strings.Join(AllowedMapDuplicates, ", "),
+		))
+		mapKeysFlag := fmt.Sprintf("flag-%s-map-keys", flagName)
+		flags.StringSliceP(mapKeysFlag, "", []string{}, fmt.Sprintf("Allowed keys for map flag %s, any key is allowed if empty", flagName))
+		mapOutputFlag := fmt.Sprintf("flag-%s-map-output", flagName)
+		flags.StringP(mapOutputFlag, "", AllowedMapOutputs[0], fmt.Sprintf(
+			"Output of map flag %s: a json object, one variable per key named <env name>_<KEY>, or a bash associative array, allowed values: %s",
+			flagName, strings.Join(AllowedMapOutputs, ", "),
+		))
+		pathChecksFlag := fmt.Sprintf("flag-%s-path-checks", flagName)
+		flags.StringSliceP(pathChecksFlag, "", []string{}, fmt.Sprintf(
+			"Checks for the value of file, dir or path flag %s, allowed values are combined of %s",
+			flagName, strings.Join(AllowedPathChecks, ", "),
+		))
+		pathNormalizeFlag := fmt.Sprintf("flag-%s-path-normalize", flagName)
+		flags.StringSliceP(pathNormalizeFlag, "", []string{}, fmt.Sprintf(
+			"Normalizations for the value of file, dir or path flag %s applied before the checks: expand '~' (home), make absolute relative to the working directory (abs), clean, allowed values are combined of %s",
+			flagName, strings.Join(AllowedPathNormalizations, ", "),
+		))
+	}
+}
@@ -0,0 +1,373 @@
+package bind
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/spf13/pflag"
+	"gopkg.in/yaml.v3"
+)
+
+// JSONSchema is the subset of JSON Schema (draft 2020-12) describing spec files,
+// the same schema is used by ValidateSpecNode when a spec file is loaded.
+type JSONSchema struct {
+	Schema               string                 `json:"$schema,omitempty"`
+	Title                string                 `json:"title,omitempty"`
+	Description          string                 `json:"description,omitempty"`
+	Type                 interface{}            `json:"type,omitempty"`
+	Enum                 []string               `json:"enum,omitempty"`
+	Pattern              string                 `json:"pattern,omitempty"`
+	Minimum              *int                   `json:"minimum,omitempty"`
+	Properties           map[string]*JSONSchema `json:"properties,omitempty"`
+	Required             []string               `json:"required,omitempty"`
+	AdditionalProperties *bool                  `json:"additionalProperties,omitempty"`
+	Items                *JSONSchema            `json:"items,omitempty"`
+	AnyOf                []*JSONSchema          `json:"anyOf,omitempty"`
+}
+
+// specOptionEnums are the allowed values of the spec file options, by key.
+func specOptionEnums() map[string][]string {
+	return map[string][]string{
+		"shell-type":        ShellTypeStrings(),
+		"env-name-sanitize": AllowedEnvNameSanitizes,
+		"output":            AllowedOutputs,
+		"help-scope":        ScopeStrings(),
+		"cmd-script":        AllowedCmdScripts,
+		"sh-declare":        AllowedShDeclares,
+		"sh-persist":        AllowedShPersists,
+		"type":              FlagTypeStrings(),
+		"unique":            AllowedUniquePolicies,
+		"sort":              AllowedSortOrders,
+		"scope":             ScopeStrings(),
+		"map-duplicate":     AllowedMapDuplicates,
+		"map-output":        AllowedMapOutputs,
+		"path-checks":       AllowedPathChecks,
+		"path-normalize":    AllowedPathNormalizations,
+	}
+}
+
+// specValueKeys are the keys holding flag values, which may be written as numbers or booleans as well.
+var specValueKeys = []string{"default", "choices", "empty-value"}
+
+// specSchemaFlagName stands for the flag name in the descriptions of the flag options.
+const specSchemaFlagName = "<name>"
+
+func specValueSchema() *JSONSchema {
+	return &JSONSchema{Type: []string{"string", "number", "boolean"}}
+}
+
+// specPropertySchema returns the schema of an option of a spec file by its key and Go type.
+func specPropertySchema(key string, t reflect.Type, usage string) *JSONSchema {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	enums := specOptionEnums()
+	var s *JSONSchema
+	switch {
+	case t == reflect.TypeOf(StringList{}):
+		s = &JSONSchema{AnyOf: []*JSONSchema{specValueSchema(), {Type: "array", Items: specValueSchema()}}}
+	case key == "multi-format":
+		s = &JSONSchema{Type: "array", Items: &JSONSchema{AnyOf: []*JSONSchema{
+			{Type: "string", Enum: AllowedMultiFormats},
+			{Type: "string", Pattern: "^" + regexp.QuoteMeta(sepMultiFormatPrefix) + ".+$"},
+		}}}
+	case t.Kind() == reflect.Slice:
+		s = &JSONSchema{Type: "array", Items: &JSONSchema{Type: "string", Enum: enums[key]}}
+	case t.Kind() == reflect.Bool:
+		s = &JSONSchema{Type: "boolean"}
+	case t.Kind() == reflect.Int:
+		minimum := 0
+		s = &JSONSchema{Type: "integer", Minimum: &minimum}
+	case slices.Contains(specValueKeys, key):
+		s = specValueSchema()
+	default:
+		s = &JSONSchema{Type: "string", Enum: enums[key]}
+	}
+	s.Description = usage
+	return s
+}
+
+// specObjectSchema returns the schema of a struct of a spec file, the descriptions are the usages of the bind options.
+func specObjectSchema(t reflect.Type, fs *pflag.FlagSet, optionPrefix string) *JSONSchema {
+	noMore := false
+	s := &JSONSchema{Type: "object", Properties: map[string]*JSONSchema{}, AdditionalProperties: &noMore}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		key, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		switch {
+		case key == "flags":
+			s.Properties[key] = &JSONSchema{
+				Description: "The flags of the command, in order",
+				Type:        "array",
+				Items:       specObjectSchema(field.Type.Elem(), fs, fmt.Sprintf("flag-%s-", specSchemaFlagName)),
+			}
+		case key == "include":
+			s.Properties[key] = &JSONSchema{
+				Description: "Spec files merged before this one, relative to this file; the options of this file override the included ones, and a flag with the name of an included flag overrides its given fields",
+				Type:        "array",
+				Items:       &JSONSchema{Type: "string"},
+			}
+		case key == "name" && optionPrefix != "":
+			s.Properties[key] = &JSONSchema{Description: "The name of the flag", Type: "string", Pattern: "^[^,]+$"}
+			s.Required = append(s.Required, key)
+		default:
+			usage := ""
+			if flag := fs.Lookup(optionPrefix + key); flag != nil {
+				usage = flag.Usage
+			}
+			s.Properties[key] = specPropertySchema(key, field.Type, usage)
+		}
+	}
+	return s
+}
+
+// SpecSchema returns the JSON Schema of spec files.
+func SpecSchema() *JSONSchema {
+	fs := pflag.NewFlagSet("bind", pflag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	addBindFlags(fs, map[string]*FlagSpec{specSchemaFlagName: {}})
+	s := specObjectSchema(reflect.TypeOf(SpecFile{}), fs, "")
+	s.Schema = "https://json-schema.org/draft/2020-12/schema"
+	s.Title = "argonaut bind spec"
+	s.Description = "The options of 'argonaut bind' as a spec file, see 'argonaut spec export'"
+	return s
+}
+
+// MarshalSpecSchema writes the JSON Schema of spec files as indented json.
+func MarshalSpecSchema() ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(SpecSchema()); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// SpecError is a violation of the schema at a path of a spec file, e.g. flags[1].multi-format[0].
+type SpecError struct {
+	Path    string
+	Line    int
+	Column  int
+	Message string
+}
+
+func (e *SpecError) Error() string {
+	path := e.Path
+	if path == "" {
+		path = "<root>"
+	}
+	return fmt.Sprintf("%s (line %d, column %d): %s", path, e.Line, e.Column, e.Message)
+}
+
+// SpecErrors are all violations of the schema found in a spec file.
+type SpecErrors []*SpecError
+
+func (errs SpecErrors) Error() string {
+	var msgs []string
+	for _, err := range errs {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// nodeTypes returns the json types a yaml node matches, an integer is a number as well.
+func nodeTypes(node *yaml.Node) []string {
+	switch node.Kind {
+	case yaml.MappingNode:
+		return []string{"object"}
+	case yaml.SequenceNode:
+		return []string{"array"}
+	case yaml.ScalarNode:
+		switch node.ShortTag() {
+		case "!!int":
+			return []string{"integer", "number"}
+		case "!!float":
+			return []string{"number"}
+		case "!!bool":
+			return []string{"boolean"}
+		case "!!null":
+			return []string{"null"}
+		default:
+			return []string{"string"}
+		}
+	}
+	return nil
+}
+
+func schemaTypes(s *JSONSchema) []string {
+	switch t := s.Type.(type) {
+	case string:
+		return []string{t}
+	case []string:
+		return t
+	}
+	return nil
+}
+
+func matchesType(s *JSONSchema, node *yaml.Node) bool {
+	types := schemaTypes(s)
+	if types == nil {
+		return true
+	}
+	for _, t := range nodeTypes(node) {
+		if slices.Contains(types, t) {
+			return true
+		}
+	}
+	return false
+}
+
+// describeSchema describes the values a schema allows for error messages.
+func describeSchema(s *JSONSchema) string {
+	if len(s.AnyOf) > 0 {
+		var parts []string
+		for _, sub := range s.AnyOf {
+			parts = append(parts, describeSchema(sub))
+		}
+		return strings.Join(parts, " or ")
+	}
+	if len(s.Enum) > 0 {
+		return fmt.Sprintf("one of %s", strings.Join(s.Enum, ", "))
+	}
+	if s.Pattern != "" {
+		return fmt.Sprintf("a string matching %s", s.Pattern)
+	}
+	if s.Items != nil {
+		return fmt.Sprintf("an array of %s", describeSchema(s.Items))
+	}
+	var types []string
+	for _, t := range schemaTypes(s) {
+		types = append(types, withArticle(t))
+	}
+	if len(types) < 2 {
+		return strings.Join(types, "")
+	}
+	return strings.Join(types[:len(types)-1], ", ") + " or " + types[len(types)-1]
+}
+
+func withArticle(t string) string {
+	if strings.ContainsAny(t[:1], "aeiou") {
+		return "an " + t
+	}
+	return "a " + t
+}
+
+type specValidator struct {
+	errs SpecErrors
+}
+
+func (v *specValidator) add(node *yaml.Node, path string, format string, args ...interface{}) {
+	v.errs = append(v.errs, &SpecError{Path: path, Line: node.Line, Column: node.Column, Message: fmt.Sprintf(format, args...)})
+}
+
+func (v *specValidator) validate(s *JSONSchema, node *yaml.Node, path string) {
+	if node.Kind == yaml.AliasNode {
+		node = node.Alias
+	}
+	if len(s.AnyOf) > 0 {
+		var matched []*JSONSchema
+		for _, sub := range s.AnyOf {
+			if matchesType(sub, node) {
+				matched = append(matched, sub)
+			}
+		}
+		for _, sub := range matched {
+			child := &specValidator{}
+			child.validate(sub, node, path)
+			if len(child.errs) == 0 {
+				return
+			}
+			if len(matched) == 1 {
+				// only one alternative has the type of the value, its errors are the most precise
+				v.errs = append(v.errs, child.errs...)
+				return
+			}
+		}
+		v.add(node, path, "%s is not %s", describeNode(node), describeSchema(s))
+		return
+	}
+	if !matchesType(s, node) {
+		v.add(node, path, "%s is not %s", describeNode(node), describeSchema(s))
+		return
+	}
+	switch node.Kind {
+	case yaml.ScalarNode:
+		if len(s.Enum) > 0 && !slices.Contains(s.Enum, node.Value) {
+			v.add(node, path, "value %q is not one of %s", node.Value, strings.Join(s.Enum, ", "))
+		}
+		if s.Pattern != "" && !regexp.MustCompile(s.Pattern).MatchString(node.Value) {
+			v.add(node, path, "value %q does not match %s", node.Value, s.Pattern)
+		}
+		if s.Minimum != nil {
+			var n int
+			if err := node.Decode(&n); err == nil && n < *s.Minimum {
+				v.add(node, path, "value %d is less than %d", n, *s.Minimum)
+			}
+		}
+	case yaml.SequenceNode:
+		if s.Items != nil {
+			for i, item := range node.Content {
+				v.validate(s.Items, item, fmt.Sprintf("%s[%d]", path, i))
+			}
+		}
+	case yaml.MappingNode:
+		seen := make(map[string]bool)
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			keyPath := key.Value
+			if path != "" {
+				keyPath = path + "." + key.Value
+			}
+			if seen[key.Value] {
+				v.add(key, keyPath, "duplicate key")
+				continue
+			}
+			seen[key.Value] = true
+			if sub, ok := s.Properties[key.Value]; ok {
+				v.validate(sub, value, keyPath)
+			} else if s.AdditionalProperties != nil && !*s.AdditionalProperties {
+				v.add(key, keyPath, "unknown key")
+			}
+		}
+		for _, required := range s.Required {
+			if !seen[required] {
+				v.add(node, path, "missing key %s", required)
+			}
+		}
+	}
+}
+
+func describeNode(node *yaml.Node) string {
+	types := nodeTypes(node)
+	if node.Kind == yaml.ScalarNode {
+		return fmt.Sprintf("%s %q", types[0], node.Value)
+	}
+	if len(types) == 0 {
+		return "a value"
+	}
+	return withArticle(types[0])
+}
+
+// ValidateSpecNode checks a parsed spec file against SpecSchema and returns all violations.
+func ValidateSpecNode(node *yaml.Node) error {
+	if node.Kind == yaml.DocumentNode {
+		if len(node.Content) == 0 {
+			return nil
+		}
+		node = node.Content[0]
+	}
+	v := &specValidator{}
+	v.validate(SpecSchema(), node, "")
+	if len(v.errs) > 0 {
+		return v.errs
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+package bind
+
+import (
+	"bytes"
+	"os"
+	"slices"
+	"testing"
+)
+
+func TestUnmarshalSpecFileErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		format string
+		data   string
+		want   string
+	}{
+		{"valid", "yaml", "name: deploy\nflags:\n  - name: env\n    default: 1\n    choices: [1, 2]\n", ""},
+		{"empty", "yaml", "", ""},
+		{"unknown_key", "yaml", "name: deploy\nflags:\n  - name: env\n    defaults: dev\n",
+			"flags[0].defaults (line 4, column 5): unknown key"},
+		{"missing_name", "yaml", "flags:\n  - short: e\n", "flags[0] (line 2, column 5): missing key name"},
+		{"enum", "yaml", "shell-type: fish\n",
+			`shell-type (line 1, column 13): value "fish" is not one of auto, sh, powershell, cmd, nushell, elvish, xonsh, csh`},
+		{"multi_format", "yaml", "flags:\n  - name: tags\n    multi-format: [comma, \"sep:\", tab]\n",
+			`flags[0].multi-format[1] (line 3, column 27): string "sep:" is not one of comma, newline, space, json, csv, nul or a string matching ^sep:.+$; ` +
+				`flags[0].multi-format[2] (line 3, column 35): string "tab" is not one of comma, newline, space, json, csv, nul or a string matching ^sep:.+$`},
+		{"type", "yaml", "debug: yes\n", `debug (line 1, column 8): string "yes" is not a boolean`},
+		{"minimum", "yaml", "flags:\n  - name: tags\n    max-items: -1\n", "flags[0].max-items (line 3, column 16): value -1 is less than 0"},
+		{"default_item", "yaml", "flags:\n  - name: tags\n    default: [a, {b: c}]\n",
+			"flags[0].default[1] (line 3, column 18): an object is not a string, a number or a boolean"},
+		{"path_checks", "json", "{\"flags\": [{\"name\": \"config\", \"path-checks\": [\"must-exist\", \"exists\"]}]}",
+			`flags[0].path-checks[1] (line 1, column 61): value "exists" is not one of must-exist, must-not-exist, readable, writable, executable`},
+		{"root", "json", "[]", "<root> (line 1, column 1): an array is not an object"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := UnmarshalSpecFile([]byte(tc.data), tc.format)
+			got := ""
+			if err != nil {
+				got = err.Error()
+			}
+			if got != tc.want {
+				t.Fatalf("got %q want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestSpecSchemaEnums(t *testing.T) {
+	s := SpecSchema()
+	if got := s.Properties["shell-type"].Enum; !slices.Equal(got, ShellTypeStrings()) {
+		t.Fatalf("got %q want %q", got, ShellTypeStrings())
+	}
+	format := s.Properties["flags"].Items.Properties["multi-format"].Items.AnyOf[0]
+	if !slices.Equal(format.Enum, AllowedMultiFormats) {
+		t.Fatalf("got %q want %q", format.Enum, AllowedMultiFormats)
+	}
+}
+
+func TestShippedSpecSchema(t *testing.T) {
+	want, err := MarshalSpecSchema()
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := os.ReadFile("../../schema/spec.schema.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Fatalf("schema/spec.schema.json is outdated, regenerate it with 'argonaut spec schema > schema/spec.schema.json'")
+	}
+}
@@ -3,11 +3,8 @@ package bind
 import (
 	"fmt"
 	"os"
-	"path/filepath"
 	"sort"
 	"strings"
-
-	"github.com/shirou/gopsutil/v4/process"
 )
 
 func calcEnvName(key string, default_value string, prefix string) string {
@@ -88,11 +85,16 @@ func buildPowershellLiteral(s string) string {
 		case "\r\n":
 			out = append(out, "\"`r`n\"")
 		default:
-			// 单引号内双写单引号以转义
-			if p == "" {
-				out = append(out, "''")
-			} else {
-				out = append(out, "'"+strings.ReplaceAll(p, "'", "''")+"'")
+			// NUL 字符只能用 "`0" 表示
+			for i, seg := range strings.Split(p, "\x00") {
+				if i > 0 {
+					out = append(out, "\"`0\"")
+				}
+				if seg == "" {
+					continue
+				}
+				// 单引号内双写单引号以转义
+				out = append(out, "'"+strings.ReplaceAll(seg, "'", "''")+"'")
 			}
 		}
 	}
@@ -100,55 +102,229 @@ func buildPowershellLiteral(s string) string {
 	return strings.Join(out, " + ")
 }
 
-// buildCmdLiteral: 保留原始换行序列，cmd 里我们使用双引号包裹片段并用 \\r \\n 文字表示换行（set/setx 中通常使用双引号）。
-func buildCmdLiteral(s string) string {
-	parts := splitPreserveNewlines(s)
-	var out []string
-	for _, p := range parts {
-		switch p {
-		case "\n":
-			out = append(out, `"\\n"`)
-		case "\r":
-			out = append(out, `"\\r"`)
-		case "\r\n":
-			out = append(out, `"\\r\\n"`)
-		default:
-			// 在 cmd 里使用双引号并对内部双引号做简单转义
-			escaped := strings.ReplaceAll(p, `"`, `\"`)
-			if escaped == "" {
-				out = append(out, `""`)
+// buildCmdLiteral 生成一段在批处理文件中执行时还原为 s 的 cmd 文本。不使用双引号包裹，
+// 因为值中的双引号会切换 cmd 的引号状态；所有特殊字符都用 ^ 转义，% 加倍为 %%，
+// 换行使用 "^" + 换行 + 空行 表示（^ 转义换行后，下一行的换行符成为字面字符）。
+// 开启延迟扩展且 s 含 ! 时，cmd 会对整行再做一轮 ^ 处理，因此 ^ 和 ! 需要额外转义。
+// cmd 读取批处理时会丢弃所有回车，因此含回车的值无法表示。
+func buildCmdLiteral(s string, delayedExpansion bool) (string, error) {
+	if strings.ContainsRune(s, '\r') {
+		return "", fmt.Errorf("carriage return cannot be represented in cmd")
+	}
+	bang := delayedExpansion && strings.ContainsRune(s, '!')
+	var b strings.Builder
+	for _, r := range s {
+		switch r {
+		case '%':
+			b.WriteString("%%")
+		case '\n':
+			b.WriteString("^\n\n")
+		case '^':
+			if bang {
+				b.WriteString("^^^^")
+			} else {
+				b.WriteString("^^")
+			}
+		case '!':
+			if bang {
+				b.WriteString("^^^!")
 			} else {
-				out = append(out, `"`+escaped+`"`)
+				b.WriteRune(r)
 			}
+		case '&', '|', '<', '>', '(', ')', '"':
+			b.WriteByte('^')
+			b.WriteRune(r)
+		default:
+			b.WriteRune(r)
 		}
 	}
-	return strings.Join(out, "")
+	return b.String(), nil
 }
 
-func exportEnvVarCmdLike(varName string, val string, export bool) string {
-	// buildCmdLiteral 保留原始换行并为 cmd 平台生成分段字面量
-	escaped := buildCmdLiteral(val)
+// cmdArgvQuote 按 CommandLineToArgvW 的规则为外部程序（如 setx）引用一个参数：
+// 双引号转义为 \"，紧挨双引号（包括结尾的双引号）的反斜杠需要加倍。
+func cmdArgvQuote(s string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	backslashes := 0
+	for _, r := range s {
+		switch r {
+		case '\\':
+			backslashes++
+			continue
+		case '"':
+			b.WriteString(strings.Repeat(`\`, backslashes*2+1))
+		default:
+			b.WriteString(strings.Repeat(`\`, backslashes))
+		}
+		b.WriteRune(r)
+		backslashes = 0
+	}
+	b.WriteString(strings.Repeat(`\`, backslashes*2))
+	b.WriteByte('"')
+	return b.String()
+}
 
-	if export {
+// exportEnvVarCmdLike 生成 cmd 赋值语句。cmd 没有不导出的变量，shell 与 env 作用域都使用 set；
+// user-persistent 作用域在 set 之外再用 setx 持久化，使当前会话立即可用。
+func exportEnvVarCmdLike(varName string, val string, scope Scope, delayedExpansion bool) ([]string, error) {
+	// session assignment: set VAR=value
+	// 注意 cmd 中赋空值等同于删除变量
+	name, err := buildCmdLiteral(varName, delayedExpansion)
+	if err != nil {
+		return nil, err
+	}
+	escaped, err := buildCmdLiteral(val, delayedExpansion)
+	if err != nil {
+		return nil, fmt.Errorf("value of %s: %w", varName, err)
+	}
+	lines := []string{fmt.Sprintf("set %s=%s", name, escaped)}
+	if scope == ScopeUserPersistent {
 		// persistent for Windows cmd: use setx
-		// setx VAR "value"
-		return fmt.Sprintf("setx %s \"%s\"", varName, escaped)
+		// setx 是外部程序，先按 CommandLineToArgvW 规则引用参数，再整体按 cmd 规则转义
+		name, err := buildCmdLiteral(cmdArgvQuote(varName), delayedExpansion)
+		if err != nil {
+			return nil, err
+		}
+		escaped, err := buildCmdLiteral(cmdArgvQuote(val), delayedExpansion)
+		if err != nil {
+			return nil, fmt.Errorf("value of %s: %w", varName, err)
+		}
+		lines = append(lines, fmt.Sprintf("setx %s %s", name, escaped))
+	}
+	return lines, nil
+}
+
+var AllowedCmdScripts = []string{"auto", "always", "never"}
+
+// writeCmdScript 把 cmd 语句写入一个临时 .cmd 文件，返回调用它的单行语句。
+// 这样即使值包含换行，调用方也只需执行一行；脚本在执行结束时删除自身。
+// 文件中的语句以 @ 开头，避免 @echo off 影响调用方的 echo 状态。
+func writeCmdScript(lines []string) (string, error) {
+	f, err := os.CreateTemp("", "argonaut-*.cmd")
+	if err != nil {
+		return "", fmt.Errorf("cannot create temp cmd script: %w", err)
+	}
+	defer f.Close()
+	var b strings.Builder
+	for _, line := range lines {
+		b.WriteString("@")
+		b.WriteString(strings.ReplaceAll(line, "\n", "\r\n"))
+		b.WriteString("\r\n")
+	}
+	b.WriteString("@(goto) 2>nul & del \"%~f0\"\r\n")
+	if _, err := f.WriteString(b.String()); err != nil {
+		return "", fmt.Errorf("cannot write temp cmd script %s: %w", f.Name(), err)
+	}
+	return fmt.Sprintf("call \"%s\"", f.Name()), nil
+}
+
+// finishCmdLines 根据 --cmd-script 决定直接输出 cmd 语句，还是写入临时脚本后输出 call 语句。
+func finishCmdLines(spec *CmdSpec, lines []string) ([]string, error) {
+	switch spec.CmdScript {
+	case "always":
+	case "auto", "":
+		multiline := false
+		for _, line := range lines {
+			if strings.Contains(line, "\n") {
+				multiline = true
+				break
+			}
+		}
+		if !multiline {
+			return lines, nil
+		}
+	case "never":
+		return lines, nil
+	default:
+		return nil, fmt.Errorf("unsupported cmd script mode: %s", spec.CmdScript)
+	}
+	line, err := writeCmdScript(lines)
+	if err != nil {
+		return nil, err
+	}
+	return []string{line}, nil
+}
+
+// unsetEnvVarCmdLike 删除变量：会话中使用 set "NAME="，user-persistent 作用域的变量还要从注册表 HKCU\Environment 中删除。
+// 名字含双引号时无法放入引号内，退回到转义后的 set NAME= 形式。
+func unsetEnvVarCmdLike(varName string, scope Scope, delayedExpansion bool) ([]string, error) {
+	var line string
+	if strings.ContainsRune(varName, '"') {
+		name, err := buildCmdLiteral(varName, delayedExpansion)
+		if err != nil {
+			return nil, err
+		}
+		line = fmt.Sprintf("set %s=", name)
 	} else {
-		// session assignment: wrap assignment in set "VAR=value"
-		// remove outer quotes if any so set "VAR=value" remains valid
-		return fmt.Sprintf("set \"%s=%s\"", varName, strings.Trim(escaped, `"`))
+		// 引号内 ^ 不再转义，只需处理 % 和延迟扩展下的 !
+		name := strings.ReplaceAll(varName, "%", "%%")
+		if delayedExpansion && strings.ContainsRune(name, '!') {
+			name = strings.ReplaceAll(strings.ReplaceAll(name, "^", "^^"), "!", "^!")
+		}
+		line = fmt.Sprintf("set \"%s=\"", name)
 	}
+	lines := []string{line}
+	if scope == ScopeUserPersistent {
+		name, err := buildCmdLiteral(cmdArgvQuote(varName), delayedExpansion)
+		if err != nil {
+			return nil, err
+		}
+		lines = append(lines, fmt.Sprintf("reg delete HKCU\\Environment /v %s /f >nul 2>&1", name))
+	}
+	return lines, nil
 }
 
-func exportEnvVarLinuxLike(varName string, val string, export bool) string {
+var AllowedShDeclares = []string{"assign", "local", "typeset", "declare"}
+
+// exportEnvVarLinuxLike 按 declare 模式生成 sh 赋值语句：
+//   - assign: NAME='v' / export NAME='v' / readonly NAME='v'
+//   - local: local NAME='v'，导出或只读时追加 "; export NAME"、"; readonly NAME"，dash 的 local 不支持选项
+//   - typeset / declare: typeset -r -x NAME='v'，在函数内同样是局部变量（ksh 需要 function f {} 语法）
+func exportEnvVarLinuxLike(varName string, val string, export bool, declare string, readonly bool) (string, error) {
 	// buildShellLiteral 保留原始换行并生成 POSIX shell 字面量片段
 	quoted := buildShellLiteral(val)
 
-	if export {
-		return fmt.Sprintf("export %s=%s", varName, quoted)
-	} else {
-		return fmt.Sprintf("%s=%s", varName, quoted)
+	switch declare {
+	case "assign", "":
+		if readonly && export {
+			return fmt.Sprintf("export %s=%s; readonly %s", varName, quoted, varName), nil
+		} else if readonly {
+			return fmt.Sprintf("readonly %s=%s", varName, quoted), nil
+		} else if export {
+			return fmt.Sprintf("export %s=%s", varName, quoted), nil
+		} else {
+			return fmt.Sprintf("%s=%s", varName, quoted), nil
+		}
+	case "local":
+		line := fmt.Sprintf("local %s=%s", varName, quoted)
+		if export {
+			line += fmt.Sprintf("; export %s", varName)
+		}
+		if readonly {
+			line += fmt.Sprintf("; readonly %s", varName)
+		}
+		return line, nil
+	case "typeset", "declare":
+		opts := ""
+		if readonly {
+			opts += " -r"
+		}
+		if export {
+			opts += " -x"
+		}
+		return fmt.Sprintf("%s%s %s=%s", declare, opts, varName, quoted), nil
+	default:
+		return "", fmt.Errorf("unsupported sh declare mode: %s", declare)
+	}
+}
+
+// unsetEnvVarLinuxLike 在 local/typeset/declare 模式下先声明局部变量再 unset，避免删除外层的同名变量。
+func unsetEnvVarLinuxLike(varName string, declare string) string {
+	if declare == "assign" || declare == "" {
+		return fmt.Sprintf("unset %s", varName)
 	}
+	return fmt.Sprintf("%s %s; unset %s", declare, varName, varName)
 }
 
 func escapeForPS(s string) string {
@@ -158,42 +334,150 @@ func escapeForPS(s string) string {
 	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
 }
 
-func exportEnvVarPowershellLike(varName string, val string, export bool) string {
+// exportEnvVarPowershellLike renders a PowerShell variable for the shell scope, an $Env: variable for the env scope,
+// and an $Env: variable persisted for the current user as well for the user-persistent scope.
+func exportEnvVarPowershellLike(varName string, val string, scope Scope) []string {
 	// buildPowershellLiteral 返回一个可作为表达式的字符串（可能含 + 连接）
 	escaped := buildPowershellLiteral(val)
 
-	if export {
-		// persistent for current user
-		return fmt.Sprintf("[System.Environment]::SetEnvironmentVariable(%s,%s,'User')", escapeForPS(varName), escaped)
-	} else {
+	switch scope {
+	case ScopeShell:
+		return []string{fmt.Sprintf("$%s = %s", varName, escaped)}
+	case ScopeUserPersistent:
+		// current session and persistent for current user
+		return []string{
+			fmt.Sprintf("$Env:%s = %s", varName, escaped),
+			fmt.Sprintf("[System.Environment]::SetEnvironmentVariable(%s,%s,'User')", escapeForPS(varName), escaped),
+		}
+	default:
 		// current session
 		// $Env:VAR = 'value'
 		// Note: env var name in PowerShell is case-insensitive, use as-is
-		return fmt.Sprintf("$Env:%s = %s", varName, escaped)
+		return []string{fmt.Sprintf("$Env:%s = %s", varName, escaped)}
 	}
 }
 
-func exportEnvVar(shellType ShellType, varName string, val string, export bool) (string, error) {
+func unsetEnvVarPowershellLike(varName string, scope Scope) []string {
+	switch scope {
+	case ScopeShell:
+		return []string{fmt.Sprintf("Remove-Variable -Name %s -ErrorAction SilentlyContinue", varName)}
+	case ScopeUserPersistent:
+		return []string{
+			fmt.Sprintf("Remove-Item -Path Env:%s -ErrorAction SilentlyContinue", varName),
+			fmt.Sprintf("[System.Environment]::SetEnvironmentVariable(%s,$null,'User')", escapeForPS(varName)),
+		}
+	default:
+		return []string{fmt.Sprintf("Remove-Item -Path Env:%s -ErrorAction SilentlyContinue", varName)}
+	}
+}
+
+// isEnvOnlyShellType reports whether the shell type only sets environment variables, since
+// variables of its own do not survive evaluating the output. The shell scope is treated as env,
+// and the user-persistent scope is not supported.
+func isEnvOnlyShellType(shellType ShellType) bool {
+	switch shellType {
+	case ShellTypeNushell, ShellTypeElvish, ShellTypeXonsh:
+		return true
+	default:
+		return false
+	}
+}
+
+// unsetEnvVar renders the statements removing the variable, in the scope where exportEnvVar would set it.
+func unsetEnvVar(shellType ShellType, spec *CmdSpec, varName string, scope Scope) ([]string, error) {
 	switch shellType {
 	case ShellTypeSh:
-		return exportEnvVarLinuxLike(varName, val, export), nil
+		return []string{unsetEnvVarLinuxLike(varName, spec.ShDeclare)}, nil
 	case ShellTypePowershell:
-		return exportEnvVarPowershellLike(varName, val, export), nil
+		return unsetEnvVarPowershellLike(varName, scope), nil
 	case ShellTypeCmd:
-		return exportEnvVarCmdLike(varName, val, export), nil
+		return unsetEnvVarCmdLike(varName, scope, spec.CmdDelayedExpansion)
+	case ShellTypeNushell:
+		return []string{unsetEnvVarNushell(varName)}, nil
+	case ShellTypeElvish:
+		return []string{unsetEnvVarElvish(varName)}, nil
+	case ShellTypeXonsh:
+		return []string{unsetEnvVarXonsh(varName)}, nil
+	case ShellTypeCsh:
+		return []string{unsetEnvVarCshLike(varName, scope)}, nil
+	default:
+		return nil, fmt.Errorf("unsupported shell type: %v", shellType)
+	}
+}
+
+// QuoteLiteral quotes s as a string literal of the shell type, the quoting used for the values of bind.
+func QuoteLiteral(shellType ShellType, s string) (string, error) {
+	switch shellType {
+	case ShellTypeSh:
+		return buildShellLiteral(s), nil
+	case ShellTypePowershell:
+		return buildPowershellLiteral(s), nil
+	case ShellTypeCmd:
+		return buildCmdLiteral(s, false)
+	case ShellTypeNushell:
+		return buildNushellLiteral(s), nil
+	case ShellTypeElvish:
+		return buildElvishLiteral(s), nil
+	case ShellTypeXonsh:
+		return buildPythonLiteral(s)
+	case ShellTypeCsh:
+		return buildCshLiteral(s), nil
 	default:
-		// should not reach here
 		return "", fmt.Errorf("unsupported shell type: %v", shellType)
 	}
 }
 
-func exportEnvVars(spec *CmdSpec) (string, error) {
-	if shellType, err := decideShellType(spec.ShellType); err != nil {
+// ExportVar renders the statements setting the variable in the scope like bind does with its default options,
+// the user-persistent scope of sh-like shells is only exported in the session.
+func ExportVar(shellType ShellType, varName string, val string, scope Scope) ([]string, error) {
+	return exportEnvVar(shellType, &CmdSpec{ShDeclare: AllowedShDeclares[0]}, varName, val, scope, false)
+}
+
+// exportEnvVar renders the statements setting the variable in the scope. For sh-like shells the
+// user-persistent scope is exported in the session here and persisted by PersistShVars.
+func exportEnvVar(shellType ShellType, spec *CmdSpec, varName string, val string, scope Scope, readonly bool) ([]string, error) {
+	if strings.ContainsRune(val, 0) && shellType != ShellTypePowershell {
+		return nil, fmt.Errorf("value of %s contains NUL characters, which variables of shell type %s cannot hold", varName, shellType)
+	}
+	switch shellType {
+	case ShellTypeSh:
+		line, err := exportEnvVarLinuxLike(varName, val, scope != ScopeShell, spec.ShDeclare, readonly)
+		if err != nil {
+			return nil, err
+		}
+		return []string{line}, nil
+	case ShellTypePowershell:
+		return exportEnvVarPowershellLike(varName, val, scope), nil
+	case ShellTypeCmd:
+		return exportEnvVarCmdLike(varName, val, scope, spec.CmdDelayedExpansion)
+	case ShellTypeNushell:
+		return []string{exportEnvVarNushell(varName, val)}, nil
+	case ShellTypeElvish:
+		return []string{exportEnvVarElvish(varName, val)}, nil
+	case ShellTypeXonsh:
+		line, err := exportEnvVarXonsh(varName, val)
+		if err != nil {
+			return nil, err
+		}
+		return []string{line}, nil
+	case ShellTypeCsh:
+		return []string{exportEnvVarCshLike(varName, val, scope)}, nil
+	default:
+		// should not reach here
+		return nil, fmt.Errorf("unsupported shell type: %v", shellType)
+	}
+}
+
+func exportEnvVars(spec *CmdSpec, env *Env) (string, error) {
+	if shellType, err := decideShellType(spec.ShellType, env); err != nil {
 		return "", err
 	} else {
 		if spec == nil || len(spec.Flags) == 0 {
 			return "", nil
 		}
+		if !isShellOutput(spec.Output) {
+			return exportOutputVars(spec, env)
+		}
 
 		// collect keys deterministic order
 		var keys []string
@@ -202,6 +486,8 @@ func exportEnvVars(spec *CmdSpec) (string, error) {
 		}
 		sort.Strings(keys)
 
+		persist := shellType == ShellTypeSh && spec.ShPersist != "" && spec.ShPersist != "none"
+		var persisted []envVarAssignment
 		var lines []string
 		for _, key := range keys {
 			fs := spec.Flags[key]
@@ -209,108 +495,83 @@ func exportEnvVars(spec *CmdSpec) (string, error) {
 				continue
 			}
 
-			// env name: prefer explicit, otherwise normalize flag key
-			varName := calcEnvName(key, fs.EnvName, spec.EnvPrefix)
+			// env name: resolved by ResolveEnvNames, otherwise prefer explicit, then normalize flag key
+			varName := fs.VarName
+			if varName == "" {
+				varName = calcEnvName(key, fs.EnvName, spec.EnvPrefix)
+			}
 
-			val, err := OutputMultiValues(fs.MultiFormat, fs.Value)
+			if fs.Missing && (fs.UnsetMissing || spec.UnsetMissing) {
+				// the variables of the keys of a previous run are unset as well
+				for _, name := range append([]string{varName}, mapKeyVarNames(varName, fs)...) {
+					unsetLines, err := unsetEnvVar(shellType, spec, name, fs.Scope)
+					if err != nil {
+						return "", fmt.Errorf("flag %s: %w", key, err)
+					}
+					lines = append(lines, unsetLines...)
+				}
+				continue
+			}
+
+			if fs.Type == FlagTypeMap {
+				mapLines, err := exportMapVar(shellType, spec, varName, fs)
+				if err != nil {
+					return "", fmt.Errorf("flag %s: %w", key, err)
+				}
+				lines = append(lines, mapLines...)
+				if persist && fs.Scope == ScopeUserPersistent {
+					assignments, err := flagAssignments(varName, fs)
+					if err != nil {
+						return "", fmt.Errorf("flag %s: %w", key, err)
+					}
+					persisted = append(persisted, assignments...)
+				}
+				continue
+			}
+
+			assignments, err := flagAssignments(varName, fs)
 			if err != nil {
 				return "", fmt.Errorf("flag %s: %w", key, err)
 			}
+			for _, a := range assignments {
+				if varLines, err := exportEnvVar(shellType, spec, a.Name, a.Value, fs.Scope, fs.Readonly); err != nil {
+					return "", err
+				} else {
+					lines = append(lines, varLines...)
+				}
+			}
+			if persist && fs.Scope == ScopeUserPersistent {
+				persisted = append(persisted, assignments...)
+			}
+		}
 
-			if line, err := exportEnvVar(shellType, varName, val, fs.Export); err != nil {
+		if persist {
+			if err := PersistShVars(spec.ShPersist, spec.Name, persisted, env); err != nil {
+				return "", err
+			}
+		}
+		if shellType == ShellTypeCmd {
+			if lines, err = finishCmdLines(spec, lines); err != nil {
 				return "", err
-			} else {
-				lines = append(lines, line)
 			}
 		}
-
 		return strings.Join(lines, "\n"), nil
 	}
 }
 
-func decideShellType(shellType ShellType) (ShellType, error) {
+func decideShellType(shellType ShellType, env *Env) (ShellType, error) {
 	switch shellType {
-	case ShellTypeSh, ShellTypePowershell, ShellTypeCmd:
+	case ShellTypeSh, ShellTypePowershell, ShellTypeCmd, ShellTypeNushell, ShellTypeElvish, ShellTypeXonsh, ShellTypeCsh:
 		return shellType, nil
 	case ShellTypeAuto:
 		fallthrough
 	default:
-		// auto-detect
-		shellName, err := detectUserShell()
+		detection, err := DetectShell(env)
 		if err != nil {
 			return ShellTypeAuto, fmt.Errorf("cannot detect user shell: %w", err)
 		}
-		shellName = strings.ToLower(shellName)
-		shellName = strings.TrimSuffix(shellName, ".exe")
-		switch shellName {
-		case "powershell", "pwsh":
-			return ShellTypePowershell, nil
-		case "cmd":
-			return ShellTypeCmd, nil
-		default:
-			// default to sh-like
-			return ShellTypeSh, nil
-		}
-	}
-}
-
-// detectUserShell 尝试返回启动当前进程的 shell 名称（如 "bash", "zsh", "pwsh", "cmd.exe" 等）。
-// 优先检查环境变量（UNIX: SHELL，Windows: COMSPEC），否则用 gopsutil 沿父进程链查找常见 shell 名称。
-func detectUserShell() (string, error) {
-
-	// 遍历父进程链寻找已知 shell
-	p, err := process.NewProcess(int32(os.Getppid()))
-	if err != nil {
-		return "", fmt.Errorf("cannot get parent process: %w", err)
-	}
-	seen := map[int32]struct{}{}
-	known := []string{
-		"bash", "zsh", "fish", "ksh", "sh", "dash", "tcsh", "csh",
-		"powershell.exe", "pwsh.exe", "cmd.exe",
-	}
-
-	for p != nil {
-		if _, ok := seen[p.Pid]; ok {
-			break
-		}
-		seen[p.Pid] = struct{}{}
-
-		name, _ := p.Name() // 可返回短名，如 "bash" 或 "powershell.exe"
-		exe, _ := p.Exe()   // 可返回完整路径
-
-		// 归一化用于匹配
-		n := strings.ToLower(name)
-		if n == "" && exe != "" {
-			n = strings.ToLower(filepath.Base(exe))
-		}
-
-		for _, k := range known {
-			kl := strings.ToLower(k)
-			if strings.Contains(n, strings.TrimSuffix(kl, ".exe")) {
-				// 返回更友好的名字（优先 name，否则 exe base）
-				if name != "" {
-					return name, nil
-				}
-				return filepath.Base(exe), nil
-			}
-		}
-
-		parent, perr := p.Parent()
-		if perr != nil || parent == nil {
-			break
-		}
-		p = parent
+		return detection.Shell.Type, nil
 	}
-
-	// 回退环境变量（UNIX: SHELL，Windows: COMSPEC）他们只是默认的 shell，不是当前实际使用的 shell
-	if sh := os.Getenv("SHELL"); sh != "" {
-		return filepath.Base(sh), nil
-	}
-	if com := os.Getenv("COMSPEC"); com != "" {
-		return filepath.Base(com), nil
-	}
-
-	return "", fmt.Errorf("user shell not detected")
 }
 
 // ...existing code...
@@ -0,0 +1,324 @@
+package bind
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+const fuzzVarName = "ARGONAUT_FUZZ"
+
+var fuzzSeeds = []string{
+	"",
+	"plain",
+	" leading and trailing ",
+	"it's",
+	"'",
+	"''",
+	`'\''`,
+	`"double" $HOME ${HOME} $(id) ` + "`id`",
+	`back\slash\`,
+	"a;b|c&d>e<f",
+	"first\nsecond\n",
+	"\r\n\r",
+	"\t tab",
+	"*?[a-z]~",
+	"!event !!",
+	"%PATH% ^&",
+	"用户-\U0001F680",
+	"\xff\xfe invalid utf-8",
+	"-n",
+	"--",
+}
+
+// fuzzShellCommand returns the command printing the variable after running line.
+// With export, the variable is printed by a child shell to check it is inherited.
+func fuzzShellCommand(shell string, line string, export bool) *exec.Cmd {
+	printCmd := `printf '%s' "$` + fuzzVarName + `"`
+	if export {
+		printCmd = "exec " + shell + " -c " + buildShellLiteral(printCmd)
+	}
+	cmd := exec.Command(shell, "-c", line+"\n"+printCmd)
+	cmd.Env = fuzzEnviron()
+	return cmd
+}
+
+func fuzzEnviron() []string {
+	var env []string
+	for _, kv := range os.Environ() {
+		if !strings.HasPrefix(kv, fuzzVarName+"=") {
+			env = append(env, kv)
+		}
+	}
+	return env
+}
+
+func FuzzExportEnvVarSh(f *testing.F) {
+	var shells []string
+	for _, shell := range []string{"sh", "bash", "dash"} {
+		if path, err := exec.LookPath(shell); err == nil {
+			shells = append(shells, path)
+		}
+	}
+	if len(shells) == 0 {
+		f.Skip("no sh-like shell found")
+	}
+	for _, seed := range fuzzSeeds {
+		f.Add(seed, false)
+		f.Add(seed, true)
+	}
+	spec := &CmdSpec{}
+	f.Fuzz(func(t *testing.T, val string, export bool) {
+		scope := ScopeShell
+		if export {
+			scope = ScopeEnv
+		}
+		lines, err := exportEnvVar(ShellTypeSh, spec, fuzzVarName, val, scope, false)
+		line := strings.Join(lines, "\n")
+		if strings.ContainsRune(val, 0) {
+			// sh variables cannot hold NUL characters
+			if err == nil {
+				t.Fatalf("expected error for value with NUL %q", val)
+			}
+			return
+		}
+		if err != nil {
+			t.Fatalf("export %q: %v", val, err)
+		}
+		for _, shell := range shells {
+			out, err := fuzzShellCommand(shell, line, export).Output()
+			if err != nil {
+				t.Fatalf("%s: run %q: %v", shell, line, err)
+			}
+			if !bytes.Equal(out, []byte(val)) {
+				t.Fatalf("%s: %q evaluated to %q, want %q", shell, line, out, val)
+			}
+		}
+	})
+}
+
+func FuzzExportEnvVarPowershell(f *testing.F) {
+	pwsh, err := exec.LookPath("pwsh")
+	if err != nil {
+		f.Skip("pwsh not found")
+	}
+	for _, seed := range fuzzSeeds {
+		f.Add(seed)
+	}
+	spec := &CmdSpec{}
+	f.Fuzz(func(t *testing.T, val string) {
+		// $Env: assignments of empty values remove the variable, and the output is read back as UTF-8
+		if val == "" || strings.ContainsRune(val, 0) || !utf8.ValidString(val) {
+			t.Skip()
+		}
+		lines, err := exportEnvVar(ShellTypePowershell, spec, fuzzVarName, val, ScopeEnv, false)
+		line := strings.Join(lines, "\n")
+		if err != nil {
+			t.Fatalf("export %q: %v", val, err)
+		}
+		script := line + "\n[Console]::Out.Write($Env:" + fuzzVarName + ")"
+		cmd := exec.Command(pwsh, "-NoProfile", "-NonInteractive", "-Command", "-")
+		cmd.Stdin = strings.NewReader(script)
+		cmd.Env = fuzzEnviron()
+		out, err := cmd.Output()
+		if err != nil {
+			t.Fatalf("pwsh: run %q: %v", line, err)
+		}
+		if !bytes.Equal(out, []byte(val)) {
+			t.Fatalf("pwsh: %q evaluated to %q, want %q", line, out, val)
+		}
+	})
+}
+
+func FuzzExportEnvVarCmd(f *testing.F) {
+	if runtime.GOOS != "windows" {
+		f.Skip("cmd is only available on windows")
+	}
+	for _, seed := range fuzzSeeds {
+		f.Add(seed, false)
+		f.Add(seed, true)
+	}
+	f.Fuzz(func(t *testing.T, val string, delayedExpansion bool) {
+		// empty values remove the variable, carriage returns cannot be represented,
+		// and non-ASCII values depend on the code page of the console
+		for _, r := range val {
+			if r == 0 || r == '\r' || r >= utf8.RuneSelf {
+				t.Skip()
+			}
+		}
+		if val == "" {
+			t.Skip()
+		}
+		spec := &CmdSpec{CmdDelayedExpansion: delayedExpansion}
+		lines, err := exportEnvVar(ShellTypeCmd, spec, fuzzVarName, val, ScopeEnv, false)
+		line := strings.Join(lines, "\n")
+		if err != nil {
+			t.Fatalf("export %q: %v", val, err)
+		}
+		script := filepath.Join(t.TempDir(), "fuzz.cmd")
+		content := "@" + strings.ReplaceAll(line, "\n", "\r\n") + "\r\n@set " + fuzzVarName + "\r\n"
+		if err := os.WriteFile(script, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		mode := "/V:OFF"
+		if delayedExpansion {
+			mode = "/V:ON"
+		}
+		cmd := exec.Command("cmd", "/D", mode, "/C", script)
+		cmd.Env = fuzzEnviron()
+		out, err := cmd.Output()
+		if err != nil {
+			t.Fatalf("cmd: run %q: %v", line, err)
+		}
+		want := fuzzVarName + "=" + val + "\r\n"
+		if string(out) != want {
+			t.Fatalf("cmd: %q evaluated to %q, want %q", line, out, want)
+		}
+	})
+}
+
+// fuzzRunScript writes script to a file named name and runs it with shell, args go before the file.
+func fuzzRunScript(t *testing.T, shell string, args []string, name string, script string, env ...string) []byte {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(script), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cmd := exec.Command(shell, append(args, path)...)
+	cmd.Env = append(fuzzEnviron(), env...)
+	out, err := cmd.Output()
+	if err != nil {
+		var stderr []byte
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			stderr = exitErr.Stderr
+		}
+		t.Fatalf("%s: run %q: %v: %s", shell, script, err, stderr)
+	}
+	return out
+}
+
+// fuzzExportLine returns the line exporting val for the shell type, it fails the test when the
+// export fails for a value the shell can hold and returns false when it cannot hold the value.
+func fuzzExportLine(t *testing.T, shellType ShellType, val string, scope Scope) (string, bool) {
+	t.Helper()
+	lines, err := exportEnvVar(shellType, &CmdSpec{}, fuzzVarName, val, scope, false)
+	if strings.ContainsRune(val, 0) {
+		if err == nil {
+			t.Fatalf("expected error for value with NUL %q", val)
+		}
+		return "", false
+	}
+	if shellType == ShellTypeXonsh && !utf8.ValidString(val) {
+		if err == nil {
+			t.Fatalf("expected error for invalid UTF-8 value %q", val)
+		}
+		return "", false
+	}
+	if err != nil {
+		t.Fatalf("export %q: %v", val, err)
+	}
+	return strings.Join(lines, "\n"), true
+}
+
+func FuzzExportEnvVarNushell(f *testing.F) {
+	nu, err := exec.LookPath("nu")
+	if err != nil {
+		f.Skip("nu not found")
+	}
+	for _, seed := range fuzzSeeds {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, val string) {
+		// nushell strings are UTF-8
+		if !utf8.ValidString(val) {
+			t.Skip()
+		}
+		line, ok := fuzzExportLine(t, ShellTypeNushell, val, ScopeEnv)
+		if !ok {
+			return
+		}
+		out := fuzzRunScript(t, nu, []string{"--no-config-file"}, "fuzz.nu", line+"\nprint -n $env."+fuzzVarName+"\n")
+		if !bytes.Equal(out, []byte(val)) {
+			t.Fatalf("nu: %q evaluated to %q, want %q", line, out, val)
+		}
+	})
+}
+
+func FuzzExportEnvVarElvish(f *testing.F) {
+	elvish, err := exec.LookPath("elvish")
+	if err != nil {
+		f.Skip("elvish not found")
+	}
+	for _, seed := range fuzzSeeds {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, val string) {
+		// elvish parses its source as UTF-8
+		if !utf8.ValidString(val) {
+			t.Skip()
+		}
+		line, ok := fuzzExportLine(t, ShellTypeElvish, val, ScopeEnv)
+		if !ok {
+			return
+		}
+		out := fuzzRunScript(t, elvish, []string{"-norc"}, "fuzz.elv", line+"\nprint $E:"+fuzzVarName+"\n")
+		if !bytes.Equal(out, []byte(val)) {
+			t.Fatalf("elvish: %q evaluated to %q, want %q", line, out, val)
+		}
+	})
+}
+
+func FuzzExportEnvVarXonsh(f *testing.F) {
+	xonsh, err := exec.LookPath("xonsh")
+	if err != nil {
+		f.Skip("xonsh not found")
+	}
+	for _, seed := range fuzzSeeds {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, val string) {
+		line, ok := fuzzExportLine(t, ShellTypeXonsh, val, ScopeEnv)
+		if !ok {
+			return
+		}
+		script := line + "\nimport sys\nsys.stdout.write($" + fuzzVarName + ")\n"
+		out := fuzzRunScript(t, xonsh, []string{"--no-rc"}, "fuzz.xsh", script, "PYTHONIOENCODING=utf-8")
+		if !bytes.Equal(out, []byte(val)) {
+			t.Fatalf("xonsh: %q evaluated to %q, want %q", line, out, val)
+		}
+	})
+}
+
+func FuzzExportEnvVarCsh(f *testing.F) {
+	var shells []string
+	for _, shell := range []string{"csh", "tcsh"} {
+		if path, err := exec.LookPath(shell); err == nil {
+			shells = append(shells, path)
+		}
+	}
+	if len(shells) == 0 {
+		f.Skip("no csh-like shell found")
+	}
+	for _, seed := range fuzzSeeds {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, val string) {
+		line, ok := fuzzExportLine(t, ShellTypeCsh, val, ScopeEnv)
+		if !ok {
+			return
+		}
+		// printenv reads the exported value back byte for byte and ends it with a line break
+		want := append([]byte(val), '\n')
+		for _, shell := range shells {
+			out := fuzzRunScript(t, shell, []string{"-f"}, "fuzz.csh", line+"\nprintenv "+fuzzVarName+"\n", "LC_ALL=C.UTF-8")
+			if !bytes.Equal(out, want) {
+				t.Fatalf("%s: %q evaluated to %q, want %q", shell, line, out, want)
+			}
+		}
+	})
+}
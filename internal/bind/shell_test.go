@@ -0,0 +1,143 @@
+package bind
+
+import (
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+func TestBuildCmdLiteral(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      string
+		delayed bool
+		want    string
+		wantErr bool
+	}{
+		{"empty", "", false, "", false},
+		{"plain", "alice", false, "alice", false},
+		{"spaces", " a b ", false, " a b ", false},
+		{"percent", "50%", false, "50%%", false},
+		{"variable", "%PATH%", false, "%%PATH%%", false},
+		{"specials", `a&b|c<d>e(f)g^h`, false, `a^&b^|c^<d^>e^(f^)g^^h`, false},
+		{"quotes", `"C:\Program Files\App"`, false, `^"C:\Program Files\App^"`, false},
+		{"bang_without_delayed", "hi!", false, "hi!", false},
+		{"bang_with_delayed", "hi!^", true, "hi^^^!^^^^", false},
+		{"caret_with_delayed_no_bang", "a^b", true, "a^^b", false},
+		{"newline", "a\nb", false, "a^\n\nb", false},
+		{"carriage_return", "a\r\nb", false, "", true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := buildCmdLiteral(tc.in, tc.delayed)
+			if tc.wantErr {
+				if err == nil {
+					t.Fatalf("expected error for %q, got %q", tc.in, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error for %q: %v", tc.in, err)
+			}
+			if got != tc.want {
+				t.Fatalf("buildCmdLiteral(%q): got %q want %q", tc.in, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestCmdArgvQuote(t *testing.T) {
+	tests := map[string]string{
+		"":           `""`,
+		"a b":        `"a b"`,
+		`a"b`:        `"a\"b"`,
+		`C:\dir\`:    `"C:\dir\\"`,
+		`a\"b`:       `"a\\\"b"`,
+		`\\server\x`: `"\\server\x"`,
+	}
+	for in, want := range tests {
+		if got := cmdArgvQuote(in); got != want {
+			t.Fatalf("cmdArgvQuote(%q): got %q want %q", in, got, want)
+		}
+	}
+}
+
+func TestWriteCmdScript(t *testing.T) {
+	t.Setenv("TMP", t.TempDir())
+	t.Setenv("TMPDIR", os.Getenv("TMP"))
+	line, err := writeCmdScript([]string{"set A=1", "set B=x^\n\ny"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(line, `call "`) || !strings.HasSuffix(line, `.cmd"`) {
+		t.Fatalf("unexpected call line %q", line)
+	}
+	path := strings.TrimSuffix(strings.TrimPrefix(line, `call "`), `"`)
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "@set A=1\r\n@set B=x^\r\n\r\ny\r\n@(goto) 2>nul & del \"%~f0\"\r\n"
+	if string(content) != want {
+		t.Fatalf("script content: got %q want %q", content, want)
+	}
+}
+
+func TestUnsetEnvVarCmdLike(t *testing.T) {
+	tests := []struct {
+		name    string
+		varName string
+		scope   Scope
+		delayed bool
+		want    string
+	}{
+		{"plain", "NAME", ScopeEnv, false, `set "NAME="`},
+		{"specials_in_quotes", "a&b^c%d", ScopeEnv, false, `set "a&b^c%%d="`},
+		{"bang_with_delayed", "a!b^c", ScopeEnv, true, `set "a^!b^^c="`},
+		{"quote_falls_back", `a"b`, ScopeEnv, false, `set a^"b=`},
+		{"user_persistent", "NAME", ScopeUserPersistent, false, "set \"NAME=\"\nreg delete HKCU\\Environment /v ^\"NAME^\" /f >nul 2>&1"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			lines, err := unsetEnvVarCmdLike(tc.varName, tc.scope, tc.delayed)
+			got := strings.Join(lines, "\n")
+			if err != nil {
+				t.Fatalf("unexpected error for %q: %v", tc.varName, err)
+			}
+			if got != tc.want {
+				t.Fatalf("unsetEnvVarCmdLike(%q): got %q want %q", tc.varName, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestExportEnvVarLinuxLikeLocal(t *testing.T) {
+	// the variable is visible inside the function, exported to its children and readonly,
+	// and the variable of the caller is untouched after the function returns
+	for _, shell := range []string{"bash", "dash"} {
+		path, err := exec.LookPath(shell)
+		if err != nil {
+			continue
+		}
+		t.Run(shell, func(t *testing.T) {
+			line, err := exportEnvVarLinuxLike("ARGONAUT_LOCAL", "in'side", true, "local", true)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			script := "ARGONAUT_LOCAL=outside\n" +
+				"f() {\n" + line + "\n" +
+				`  sh -c 'printf "%s|" "$ARGONAUT_LOCAL"'` + "\n" +
+				"  (ARGONAUT_LOCAL=changed) 2>/dev/null || printf 'readonly|'\n" +
+				"}\n" +
+				`f; printf "%s" "$ARGONAUT_LOCAL"`
+			out, err := exec.Command(path, "-c", script).CombinedOutput()
+			if err != nil {
+				t.Fatalf("%s failed: %v: %s", shell, err, out)
+			}
+			if want := "in'side|readonly|outside"; string(out) != want {
+				t.Fatalf("got %q want %q", out, want)
+			}
+		})
+	}
+}
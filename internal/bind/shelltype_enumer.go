@@ -7,11 +7,11 @@ import (
 	"strings"
 )
 
-const _ShellTypeName = "autoshpowershellcmd"
+const _ShellTypeName = "autoshpowershellcmdnushellelvishxonshcsh"
 
-var _ShellTypeIndex = [...]uint8{0, 4, 6, 16, 19}
+var _ShellTypeIndex = [...]uint8{0, 4, 6, 16, 19, 26, 32, 37, 40}
 
-const _ShellTypeLowerName = "autoshpowershellcmd"
+const _ShellTypeLowerName = "autoshpowershellcmdnushellelvishxonshcsh"
 
 func (i ShellType) String() string {
 	if i < 0 || i >= ShellType(len(_ShellTypeIndex)-1) {
@@ -28,9 +28,13 @@ func _ShellTypeNoOp() {
 	_ = x[ShellTypeSh-(1)]
 	_ = x[ShellTypePowershell-(2)]
 	_ = x[ShellTypeCmd-(3)]
+	_ = x[ShellTypeNushell-(4)]
+	_ = x[ShellTypeElvish-(5)]
+	_ = x[ShellTypeXonsh-(6)]
+	_ = x[ShellTypeCsh-(7)]
 }
 
-var _ShellTypeValues = []ShellType{ShellTypeAuto, ShellTypeSh, ShellTypePowershell, ShellTypeCmd}
+var _ShellTypeValues = []ShellType{ShellTypeAuto, ShellTypeSh, ShellTypePowershell, ShellTypeCmd, ShellTypeNushell, ShellTypeElvish, ShellTypeXonsh, ShellTypeCsh}
 
 var _ShellTypeNameToValueMap = map[string]ShellType{
 	_ShellTypeName[0:4]:        ShellTypeAuto,
@@ -41,6 +45,14 @@ var _ShellTypeNameToValueMap = map[string]ShellType{
 	_ShellTypeLowerName[6:16]:  ShellTypePowershell,
 	_ShellTypeName[16:19]:      ShellTypeCmd,
 	_ShellTypeLowerName[16:19]: ShellTypeCmd,
+	_ShellTypeName[19:26]:      ShellTypeNushell,
+	_ShellTypeLowerName[19:26]: ShellTypeNushell,
+	_ShellTypeName[26:32]:      ShellTypeElvish,
+	_ShellTypeLowerName[26:32]: ShellTypeElvish,
+	_ShellTypeName[32:37]:      ShellTypeXonsh,
+	_ShellTypeLowerName[32:37]: ShellTypeXonsh,
+	_ShellTypeName[37:40]:      ShellTypeCsh,
+	_ShellTypeLowerName[37:40]: ShellTypeCsh,
 }
 
 var _ShellTypeNames = []string{
@@ -48,6 +60,10 @@ var _ShellTypeNames = []string{
 	_ShellTypeName[4:6],
 	_ShellTypeName[6:16],
 	_ShellTypeName[16:19],
+	_ShellTypeName[19:26],
+	_ShellTypeName[26:32],
+	_ShellTypeName[32:37],
+	_ShellTypeName[37:40],
 }
 
 // ShellTypeString retrieves an enum value from the enum constants string name.
@@ -0,0 +1,500 @@
+package bind
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"gopkg.in/yaml.v3"
+)
+
+// AllowedSpecFormats are the formats of a spec file, a file is read as json if its extension is .json and as yaml otherwise.
+var AllowedSpecFormats = []string{"yaml", "json"}
+
+// StringList is a list of strings which is written as a scalar if it holds a single item.
+type StringList []string
+
+func (l StringList) MarshalYAML() (interface{}, error) {
+	if len(l) == 1 {
+		return l[0], nil
+	}
+	return []string(l), nil
+}
+
+func (l *StringList) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		*l = StringList{node.Value}
+		return nil
+	}
+	var items []string
+	if err := node.Decode(&items); err != nil {
+		return err
+	}
+	*l = items
+	return nil
+}
+
+func (l StringList) MarshalJSON() ([]byte, error) {
+	if len(l) == 1 {
+		return json.Marshal(l[0])
+	}
+	return json.Marshal([]string(l))
+}
+
+func (l *StringList) UnmarshalJSON(data []byte) error {
+	var item string
+	if err := json.Unmarshal(data, &item); err == nil {
+		*l = StringList{item}
+		return nil
+	}
+	var items []string
+	if err := json.Unmarshal(data, &items); err != nil {
+		return err
+	}
+	*l = items
+	return nil
+}
+
+// SpecFile is the content of a spec file. Its fields mirror the options of bind, a nil field means
+// the option is not given, so rendering it back to options gives the same CmdSpec.
+type SpecFile struct {
+	Include             []string   `yaml:"include,omitempty" json:"include,omitempty"`
+	Name                *string    `yaml:"name,omitempty" json:"name,omitempty"`
+	Short               *string    `yaml:"short,omitempty" json:"short,omitempty"`
+	Long                *string    `yaml:"long,omitempty" json:"long,omitempty"`
+	EnvPrefix           *string    `yaml:"env-prefix,omitempty" json:"env-prefix,omitempty"`
+	AllowRepeatedFlags  *bool      `yaml:"allow-repeated-flags,omitempty" json:"allow-repeated-flags,omitempty"`
+	Debug               *bool      `yaml:"debug,omitempty" json:"debug,omitempty"`
+	Explain             *bool      `yaml:"explain,omitempty" json:"explain,omitempty"`
+	ShellType           *string    `yaml:"shell-type,omitempty" json:"shell-type,omitempty"`
+	EnvNameSanitize     *string    `yaml:"env-name-sanitize,omitempty" json:"env-name-sanitize,omitempty"`
+	Output              *string    `yaml:"output,omitempty" json:"output,omitempty"`
+	OutputFile          *string    `yaml:"output-file,omitempty" json:"output-file,omitempty"`
+	ArgsRange           *string    `yaml:"args-range,omitempty" json:"args-range,omitempty"`
+	HelpVar             *string    `yaml:"help-var,omitempty" json:"help-var,omitempty"`
+	HelpExport          *bool      `yaml:"help-export,omitempty" json:"help-export,omitempty"`
+	HelpScope           *string    `yaml:"help-scope,omitempty" json:"help-scope,omitempty"`
+	CmdScript           *string    `yaml:"cmd-script,omitempty" json:"cmd-script,omitempty"`
+	CmdDelayedExpansion *bool      `yaml:"cmd-delayed-expansion,omitempty" json:"cmd-delayed-expansion,omitempty"`
+	UnsetMissing        *bool      `yaml:"unset-missing,omitempty" json:"unset-missing,omitempty"`
+	ShDeclare           *string    `yaml:"sh-declare,omitempty" json:"sh-declare,omitempty"`
+	ShPersist           *string    `yaml:"sh-persist,omitempty" json:"sh-persist,omitempty"`
+	Flags               []FlagFile `yaml:"flags,omitempty" json:"flags,omitempty"`
+}
+
+// FlagFile is a flag of a spec file, its fields mirror the --flag-<name>-* options of bind.
+type FlagFile struct {
+	Name          string      `yaml:"name" json:"name"`
+	Short         *string     `yaml:"short,omitempty" json:"short,omitempty"`
+	Helper        *string     `yaml:"helper,omitempty" json:"helper,omitempty"`
+	Type          *string     `yaml:"type,omitempty" json:"type,omitempty"`
+	Multi         *bool       `yaml:"multi,omitempty" json:"multi,omitempty"`
+	MultiFormat   []string    `yaml:"multi-format,omitempty" json:"multi-format,omitempty"`
+	MultiPreserve *bool       `yaml:"multi-preserve,omitempty" json:"multi-preserve,omitempty"`
+	Unique        *string     `yaml:"unique,omitempty" json:"unique,omitempty"`
+	Sort          *string     `yaml:"sort,omitempty" json:"sort,omitempty"`
+	MaxItems      *int        `yaml:"max-items,omitempty" json:"max-items,omitempty"`
+	Default       *StringList `yaml:"default,omitempty" json:"default,omitempty"`
+	EmptyValue    *string     `yaml:"empty-value,omitempty" json:"empty-value,omitempty"`
+	Choices       StringList  `yaml:"choices,omitempty" json:"choices,omitempty"`
+	Required      *bool       `yaml:"required,omitempty" json:"required,omitempty"`
+	EnvName       *string     `yaml:"env-name,omitempty" json:"env-name,omitempty"`
+	Export        *bool       `yaml:"export,omitempty" json:"export,omitempty"`
+	Scope         *string     `yaml:"scope,omitempty" json:"scope,omitempty"`
+	UnsetMissing  *bool       `yaml:"unset-missing,omitempty" json:"unset-missing,omitempty"`
+	Readonly      *bool       `yaml:"readonly,omitempty" json:"readonly,omitempty"`
+	MapDuplicate  *string     `yaml:"map-duplicate,omitempty" json:"map-duplicate,omitempty"`
+	MapKeys       []string    `yaml:"map-keys,omitempty" json:"map-keys,omitempty"`
+	MapOutput     *string     `yaml:"map-output,omitempty" json:"map-output,omitempty"`
+	PathChecks    []string    `yaml:"path-checks,omitempty" json:"path-checks,omitempty"`
+	PathNormalize []string    `yaml:"path-normalize,omitempty" json:"path-normalize,omitempty"`
+}
+
+func changedString(fs *pflag.FlagSet, name string) *string {
+	if !fs.Changed(name) {
+		return nil
+	}
+	v, _ := fs.GetString(name)
+	return &v
+}
+
+func changedBool(fs *pflag.FlagSet, name string) *bool {
+	if !fs.Changed(name) {
+		return nil
+	}
+	v, _ := fs.GetBool(name)
+	return &v
+}
+
+func changedInt(fs *pflag.FlagSet, name string) *int {
+	if !fs.Changed(name) {
+		return nil
+	}
+	v, _ := fs.GetInt(name)
+	return &v
+}
+
+func changedStringSlice(fs *pflag.FlagSet, name string) []string {
+	if !fs.Changed(name) {
+		return nil
+	}
+	v, _ := fs.GetStringSlice(name)
+	return v
+}
+
+func uniqueFlagsName(names []string) []string {
+	var result []string
+	seen := make(map[string]bool)
+	for _, name := range names {
+		if !seen[name] {
+			seen[name] = true
+			result = append(result, name)
+		}
+	}
+	return result
+}
+
+// ExportSpec converts the bind options to a spec file, keeping only the options which are given.
+// The options are checked the same way bind does, without user arguments, with the environment variables of env.
+func ExportSpec(bindArgs []string, env *Env) (*SpecFile, error) {
+	bindArgs, err := ExpandSpecArgs(bindArgs)
+	if err != nil {
+		return nil, err
+	}
+	if spec, err := collectSpecs(&cobra.Command{Use: "argonaut"}, bindArgs, []string{"spec"}, env.quiet()); err != nil {
+		return nil, err
+	} else if spec == nil {
+		return nil, fmt.Errorf("the help flag cannot be exported")
+	}
+	flagsName, err := collectFlagsName(bindArgs)
+	if err != nil {
+		return nil, err
+	}
+	flagsName = uniqueFlagsName(flagsName)
+	specs := make(map[string]*FlagSpec)
+	if err := collectFlagsMulti(specs, flagsName, bindArgs); err != nil {
+		return nil, err
+	}
+	fs := pflag.NewFlagSet("bind", pflag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	addBindFlags(fs, specs)
+	if err := fs.Parse(bindArgs); err != nil {
+		return nil, err
+	}
+	f := &SpecFile{
+		Name:                changedString(fs, "name"),
+		Short:               changedString(fs, "short"),
+		Long:                changedString(fs, "long"),
+		EnvPrefix:           changedString(fs, "env-prefix"),
+		AllowRepeatedFlags:  changedBool(fs, "allow-repeated-flags"),
+		Debug:               changedBool(fs, "debug"),
+		Explain:             changedBool(fs, "explain"),
+		ShellType:           changedString(fs, "shell-type"),
+		EnvNameSanitize:     changedString(fs, "env-name-sanitize"),
+		Output:              changedString(fs, "output"),
+		OutputFile:          changedString(fs, "output-file"),
+		ArgsRange:           changedString(fs, "args-range"),
+		HelpVar:             changedString(fs, "help-var"),
+		HelpExport:          changedBool(fs, "help-export"),
+		HelpScope:           changedString(fs, "help-scope"),
+		CmdScript:           changedString(fs, "cmd-script"),
+		CmdDelayedExpansion: changedBool(fs, "cmd-delayed-expansion"),
+		UnsetMissing:        changedBool(fs, "unset-missing"),
+		ShDeclare:           changedString(fs, "sh-declare"),
+		ShPersist:           changedString(fs, "sh-persist"),
+	}
+	for _, flagName := range flagsName {
+		option := func(name string) string {
+			return fmt.Sprintf("flag-%s-%s", flagName, name)
+		}
+		ff := FlagFile{
+			Name:          flagName,
+			Short:         changedString(fs, option("short")),
+			Helper:        changedString(fs, option("helper")),
+			Type:          changedString(fs, option("type")),
+			Multi:         changedBool(fs, option("multi")),
+			MultiPreserve: changedBool(fs, option("multi-preserve")),
+			Unique:        changedString(fs, option("unique")),
+			Sort:          changedString(fs, option("sort")),
+			MaxItems:      changedInt(fs, option("max-items")),
+			EmptyValue:    changedString(fs, option("empty-value")),
+			Required:      changedBool(fs, option("required")),
+			EnvName:       changedString(fs, option("env-name")),
+			Export:        changedBool(fs, option("export")),
+			Scope:         changedString(fs, option("scope")),
+			UnsetMissing:  changedBool(fs, option("unset-missing")),
+			Readonly:      changedBool(fs, option("readonly")),
+			MapDuplicate:  changedString(fs, option("map-duplicate")),
+			MapKeys:       changedStringSlice(fs, option("map-keys")),
+			MapOutput:     changedString(fs, option("map-output")),
+			PathChecks:    changedStringSlice(fs, option("path-checks")),
+			PathNormalize: changedStringSlice(fs, option("path-normalize")),
+		}
+		if fs.Changed(option("multi-format")) {
+			// repeated multi formats are combined, so the parsed ones are kept instead of the last option
+			ff.MultiFormat = specs[flagName].MultiFormat
+		}
+		if fs.Changed(option("default")) {
+			var values StringList
+			if specs[flagName].Multi || specs[flagName].Type == FlagTypeMap {
+				values, _ = fs.GetStringArray(option("default"))
+			} else {
+				value, _ := fs.GetString(option("default"))
+				values = StringList{value}
+			}
+			ff.Default = &values
+		}
+		if fs.Changed(option("choices")) {
+			ff.Choices, _ = fs.GetStringArray(option("choices"))
+		}
+		f.Flags = append(f.Flags, ff)
+	}
+	return f, nil
+}
+
+type specRenderer struct {
+	args []string
+}
+
+func (r *specRenderer) str(name string, v *string) {
+	if v != nil {
+		r.args = append(r.args, fmt.Sprintf("--%s=%s", name, *v))
+	}
+}
+
+func (r *specRenderer) bool(name string, v *bool) {
+	if v != nil {
+		r.args = append(r.args, fmt.Sprintf("--%s=%t", name, *v))
+	}
+}
+
+func (r *specRenderer) int(name string, v *int) {
+	if v != nil {
+		r.args = append(r.args, fmt.Sprintf("--%s=%d", name, *v))
+	}
+}
+
+func (r *specRenderer) list(name string, v []string) error {
+	if len(v) == 0 {
+		return nil
+	}
+	// the option is a string slice, which is read as a csv record
+	value, err := outputCsvValues(v)
+	if err != nil {
+		return err
+	}
+	r.args = append(r.args, fmt.Sprintf("--%s=%s", name, value))
+	return nil
+}
+
+func (r *specRenderer) each(name string, v []string) {
+	for _, item := range v {
+		r.args = append(r.args, fmt.Sprintf("--%s=%s", name, item))
+	}
+}
+
+// RenderSpecArgs converts a spec file back to bind options, one option per argument.
+func RenderSpecArgs(f *SpecFile) ([]string, error) {
+	if len(f.Include) > 0 {
+		return nil, fmt.Errorf("the includes of the spec file must be resolved before rendering, see LoadSpecFile")
+	}
+	r := &specRenderer{}
+	r.str("name", f.Name)
+	r.str("short", f.Short)
+	r.str("long", f.Long)
+	r.str("env-prefix", f.EnvPrefix)
+	r.bool("allow-repeated-flags", f.AllowRepeatedFlags)
+	r.bool("debug", f.Debug)
+	r.bool("explain", f.Explain)
+	r.str("shell-type", f.ShellType)
+	r.str("env-name-sanitize", f.EnvNameSanitize)
+	r.str("output", f.Output)
+	r.str("output-file", f.OutputFile)
+	r.str("args-range", f.ArgsRange)
+	r.str("help-var", f.HelpVar)
+	r.bool("help-export", f.HelpExport)
+	r.str("help-scope", f.HelpScope)
+	r.str("cmd-script", f.CmdScript)
+	r.bool("cmd-delayed-expansion", f.CmdDelayedExpansion)
+	r.bool("unset-missing", f.UnsetMissing)
+	r.str("sh-declare", f.ShDeclare)
+	r.str("sh-persist", f.ShPersist)
+	for i, ff := range f.Flags {
+		if ff.Name == "" || strings.Contains(ff.Name, ",") {
+			return nil, fmt.Errorf("invalid name %q of flag %d", ff.Name, i+1)
+		}
+		option := func(name string) string {
+			return fmt.Sprintf("flag-%s-%s", ff.Name, name)
+		}
+		r.args = append(r.args, fmt.Sprintf("--flag=%s", ff.Name))
+		r.str(option("short"), ff.Short)
+		r.str(option("helper"), ff.Helper)
+		r.str(option("type"), ff.Type)
+		r.bool(option("multi"), ff.Multi)
+		if err := r.list(option("multi-format"), ff.MultiFormat); err != nil {
+			return nil, err
+		}
+		r.bool(option("multi-preserve"), ff.MultiPreserve)
+		r.str(option("unique"), ff.Unique)
+		r.str(option("sort"), ff.Sort)
+		r.int(option("max-items"), ff.MaxItems)
+		if ff.Default != nil {
+			r.each(option("default"), *ff.Default)
+		}
+		r.str(option("empty-value"), ff.EmptyValue)
+		r.each(option("choices"), ff.Choices)
+		r.bool(option("required"), ff.Required)
+		r.str(option("env-name"), ff.EnvName)
+		r.bool(option("export"), ff.Export)
+		r.str(option("scope"), ff.Scope)
+		r.bool(option("unset-missing"), ff.UnsetMissing)
+		r.bool(option("readonly"), ff.Readonly)
+		r.str(option("map-duplicate"), ff.MapDuplicate)
+		if err := r.list(option("map-keys"), ff.MapKeys); err != nil {
+			return nil, err
+		}
+		r.str(option("map-output"), ff.MapOutput)
+		if err := r.list(option("path-checks"), ff.PathChecks); err != nil {
+			return nil, err
+		}
+		if err := r.list(option("path-normalize"), ff.PathNormalize); err != nil {
+			return nil, err
+		}
+	}
+	return r.args, nil
+}
+
+var plainShellWord = regexp.MustCompile(`^[A-Za-z0-9_@%+=:,./-]+$`)
+
+// RenderSpecShell renders the bind options of a spec file as sh words, one option per line, to paste into a script.
+func RenderSpecShell(f *SpecFile) (string, error) {
+	args, err := RenderSpecArgs(f)
+	if err != nil {
+		return "", err
+	}
+	words := make([]string, len(args))
+	for i, arg := range args {
+		if plainShellWord.MatchString(arg) {
+			words[i] = arg
+		} else {
+			words[i] = buildShellLiteral(arg)
+		}
+	}
+	return strings.Join(words, " \\\n"), nil
+}
+
+// MarshalSpecFile writes a spec file in the format, one of AllowedSpecFormats.
+func MarshalSpecFile(f *SpecFile, format string) ([]byte, error) {
+	switch format {
+	case "json":
+		data, err := json.MarshalIndent(f, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		return append(data, '\n'), nil
+	case "yaml":
+		var buf bytes.Buffer
+		enc := yaml.NewEncoder(&buf)
+		enc.SetIndent(2)
+		if err := enc.Encode(f); err != nil {
+			return nil, err
+		}
+		if err := enc.Close(); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	default:
+		return nil, fmt.Errorf("invalid spec format: %s, allowed formats are: %v", format, AllowedSpecFormats)
+	}
+}
+
+// UnmarshalSpecFile reads a spec file in the format, one of AllowedSpecFormats, and checks it against SpecSchema.
+func UnmarshalSpecFile(data []byte, format string) (*SpecFile, error) {
+	switch format {
+	case "json":
+		if !json.Valid(data) {
+			var v interface{}
+			return nil, json.Unmarshal(data, &v)
+		}
+	case "yaml":
+	default:
+		return nil, fmt.Errorf("invalid spec format: %s, allowed formats are: %v", format, AllowedSpecFormats)
+	}
+	// json is read as yaml as well, so the errors point to the lines of both
+	var node yaml.Node
+	if err := yaml.Unmarshal(data, &node); err != nil {
+		return nil, err
+	}
+	f := &SpecFile{}
+	if node.Kind == 0 {
+		// an empty file
+		return f, nil
+	}
+	if err := ValidateSpecNode(&node); err != nil {
+		return nil, err
+	}
+	if err := node.Decode(f); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// specFileFormat returns the format of a spec file by its extension.
+func specFileFormat(path string) string {
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		return "json"
+	}
+	return "yaml"
+}
+
+// readSpecFile reads the spec file at path without resolving its includes.
+func readSpecFile(path string) (*SpecFile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read spec file: %w", err)
+	}
+	f, err := UnmarshalSpecFile(data, specFileFormat(path))
+	if err != nil {
+		return nil, fmt.Errorf("invalid spec file %s: %w", path, err)
+	}
+	return f, nil
+}
+
+// ExpandSpecArgs replaces every --spec option of the bind options with the options rendered from its spec file,
+// so the options given after it override the ones of the file.
+func ExpandSpecArgs(bindArgs []string) ([]string, error) {
+	var result []string
+	for i := 0; i < len(bindArgs); i++ {
+		arg := bindArgs[i]
+		var path string
+		if arg == "--spec" {
+			if i+1 >= len(bindArgs) {
+				return nil, fmt.Errorf("flag needs an argument: --spec")
+			}
+			i++
+			path = bindArgs[i]
+		} else if value, ok := strings.CutPrefix(arg, "--spec="); ok {
+			path = value
+		} else {
+			result = append(result, arg)
+			continue
+		}
+		f, err := LoadSpecFile(path)
+		if err != nil {
+			return nil, err
+		}
+		args, err := RenderSpecArgs(f)
+		if err != nil {
+			return nil, fmt.Errorf("invalid spec file %s: %w", path, err)
+		}
+		result = append(result, args...)
+	}
+	return result, nil
+}
@@ -0,0 +1,125 @@
+package bind
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+func collectQuietSpecs(t *testing.T, args []string) *CmdSpec {
+	t.Helper()
+	spec, err := collectSpecs(&cobra.Command{Use: "argonaut"}, args, []string{"script"}, testEnv(nil))
+	if err != nil {
+		t.Fatalf("collect specs of %q: %v", args, err)
+	}
+	return spec
+}
+
+func TestSpecFileRoundTrip(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+	}{
+		{"empty", []string{}},
+		{"sep_format", []string{"--flag=path", "--flag-path-multi", "--flag-path-multi-format=\"sep:,\"", "--flag-path-default=a,b"}},
+		{"globals", []string{
+			"--name=deploy", "--short=Deploy it", "--long=Deploy it\nfor real", "--env-prefix=APP_", "--debug",
+			"--env-name-sanitize=error", "--args-range=[1,3]", "--help-var=DEPLOY_HELP", "--help-scope=env",
+			"--unset-missing", "--sh-declare=local",
+		}},
+		{"str_flag", []string{
+			"--flag=name", "--flag-name-short=n", "--flag-name-helper=The name, it's quoted", "--flag-name-default=a b",
+			"--flag-name-choices=", "--flag-name-choices=a b", "--flag-name-required", "--flag-name-env-name=USER_NAME",
+			"--flag-name-scope=env", "--flag-name-readonly", "--flag-name-unset-missing=false",
+		}},
+		{"multi_flag", []string{
+			"--flag=tags", "--flag-tags-multi", "--flag-tags-multi-format=comma", "--flag-tags-multi-format=newline",
+			"--flag-tags-unique=error", "--flag-tags-sort=asc", "--flag-tags-max-items=3",
+			"--flag-tags-default=a", "--flag-tags-default=b", "--flag-tags-choices=a,b,c",
+		}},
+		{"map_flag", []string{
+			"--flag=labels", "--flag-labels-type=map", "--flag-labels-map-keys=app,\"a,b\"", "--flag-labels-map-output=vars",
+			"--flag-labels-map-duplicate=error", "--flag-labels-default=app=web",
+		}},
+		{"path_flag", []string{
+			"--flag=config", "--flag-config-type=file", "--flag-config-path-checks=must-exist,readable",
+			"--flag-config-path-normalize=home,abs", "--flag=verbose", "--flag-verbose-type=count", "--flag-verbose-short=v",
+		}},
+		{"repeated_flags", []string{"--allow-repeated-flags", "--flag=name", "--flag=name", "--flag-name-empty-value=x"}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			args := append([]string{"--shell-type=sh"}, tc.args...)
+			want := collectQuietSpecs(t, args)
+			f, err := ExportSpec(args, testEnv(nil))
+			if err != nil {
+				t.Fatalf("export: %v", err)
+			}
+			for _, format := range AllowedSpecFormats {
+				data, err := MarshalSpecFile(f, format)
+				if err != nil {
+					t.Fatalf("marshal %s: %v", format, err)
+				}
+				loaded, err := UnmarshalSpecFile(data, format)
+				if err != nil {
+					t.Fatalf("unmarshal %s: %v\n%s", format, err, data)
+				}
+				args, err := RenderSpecArgs(loaded)
+				if err != nil {
+					t.Fatalf("render %s: %v", format, err)
+				}
+				if got := collectQuietSpecs(t, args); !reflect.DeepEqual(got, want) {
+					t.Fatalf("%s: got %+v want %+v\n%s", format, got, want, data)
+				}
+			}
+		})
+	}
+}
+
+func TestExpandSpecArgs(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "spec.yaml")
+	content := "name: deploy\nflags:\n  - name: env\n    default: dev\n    choices: [dev, prod]\n"
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	got, err := ExpandSpecArgs([]string{"--shell-type=sh", "--spec", path, "--flag-env-default=prod"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"--shell-type=sh", "--name=deploy", "--flag=env", "--flag-env-default=dev",
+		"--flag-env-choices=dev", "--flag-env-choices=prod", "--flag-env-default=prod",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %q want %q", got, want)
+	}
+	spec := collectQuietSpecs(t, got)
+	if !reflect.DeepEqual(spec.Flags["env"].Default, []string{"prod"}) {
+		t.Fatalf("got default %q want %q", spec.Flags["env"].Default, []string{"prod"})
+	}
+
+	bad := filepath.Join(dir, "bad.json")
+	if err := os.WriteFile(bad, []byte(`{"flags": [{"name": "env", "defaults": "dev"}]}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ExpandSpecArgs([]string{"--spec=" + bad}); err == nil {
+		t.Fatalf("want error for unknown field")
+	}
+}
+
+func TestRenderSpecShell(t *testing.T) {
+	name, helper := "deploy", "it's here"
+	f := &SpecFile{Name: &name, Flags: []FlagFile{{Name: "env", Helper: &helper}}}
+	got, err := RenderSpecShell(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "--name=deploy \\\n--flag=env \\\n'--flag-env-helper=it'\\''s here'"
+	if got != want {
+		t.Fatalf("got %q want %q", got, want)
+	}
+}
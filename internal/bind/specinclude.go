@@ -0,0 +1,146 @@
+package bind
+
+import (
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"strings"
+)
+
+// specMerger merges spec files into one, remembering which file set each option and flag
+// so conflicting definitions of included files can be reported.
+type specMerger struct {
+	f       *SpecFile
+	origins map[string]string
+	flags   map[string]int
+}
+
+func newSpecMerger() *specMerger {
+	return &specMerger{f: &SpecFile{}, origins: make(map[string]string), flags: make(map[string]int)}
+}
+
+// specFieldKey returns the key of a field of a spec file, e.g. shell-type.
+func specFieldKey(field reflect.StructField) string {
+	key, _, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+	return key
+}
+
+// include merges an included spec file, an option or flag which is already set by another
+// included file must be the same.
+func (m *specMerger) include(f *SpecFile, origin string) error {
+	dst, src := reflect.ValueOf(m.f).Elem(), reflect.ValueOf(f).Elem()
+	for i := 0; i < src.NumField(); i++ {
+		key := specFieldKey(src.Type().Field(i))
+		if key == "include" || key == "flags" || src.Field(i).IsNil() {
+			continue
+		}
+		if !dst.Field(i).IsNil() {
+			if !reflect.DeepEqual(dst.Field(i).Interface(), src.Field(i).Interface()) {
+				return fmt.Errorf("conflicting definitions of %s in %s and %s", key, m.origins[key], origin)
+			}
+			continue
+		}
+		dst.Field(i).Set(src.Field(i))
+		m.origins[key] = origin
+	}
+	for _, ff := range f.Flags {
+		if index, exists := m.flags[ff.Name]; exists {
+			if !reflect.DeepEqual(m.f.Flags[index], ff) {
+				return fmt.Errorf("conflicting definitions of flag %s in %s and %s", ff.Name, m.origins["flag "+ff.Name], origin)
+			}
+			continue
+		}
+		m.flags[ff.Name] = len(m.f.Flags)
+		m.f.Flags = append(m.f.Flags, ff)
+		m.origins["flag "+ff.Name] = origin
+	}
+	return nil
+}
+
+// override merges the including spec file, whose options replace the included ones, and whose
+// flags replace the given fields of an included flag with the same name.
+func (m *specMerger) override(f *SpecFile) {
+	dst, src := reflect.ValueOf(m.f).Elem(), reflect.ValueOf(f).Elem()
+	for i := 0; i < src.NumField(); i++ {
+		key := specFieldKey(src.Type().Field(i))
+		if key == "include" || key == "flags" || src.Field(i).IsNil() {
+			continue
+		}
+		dst.Field(i).Set(src.Field(i))
+	}
+	for _, ff := range f.Flags {
+		index, exists := m.flags[ff.Name]
+		if !exists {
+			m.flags[ff.Name] = len(m.f.Flags)
+			m.f.Flags = append(m.f.Flags, ff)
+			continue
+		}
+		dstFlag, srcFlag := reflect.ValueOf(&m.f.Flags[index]).Elem(), reflect.ValueOf(ff)
+		for j := 0; j < srcFlag.NumField(); j++ {
+			// every field but the name is a pointer or a slice, nil if not given
+			if srcFlag.Type().Field(j).Name != "Name" && !srcFlag.Field(j).IsNil() {
+				dstFlag.Field(j).Set(srcFlag.Field(j))
+			}
+		}
+	}
+}
+
+// duplicateSpecFlag returns the name of a flag defined twice in a spec file.
+func duplicateSpecFlag(f *SpecFile) string {
+	seen := make(map[string]bool)
+	for _, ff := range f.Flags {
+		if seen[ff.Name] {
+			return ff.Name
+		}
+		seen[ff.Name] = true
+	}
+	return ""
+}
+
+// loadSpecFileIncludes reads the spec file at path and merges its includes, chain holds the
+// absolute paths of the including files to detect cycles.
+func loadSpecFileIncludes(path string, chain []string, names []string) (*SpecFile, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+	for i, including := range chain {
+		if including == abs {
+			cycle := append(append([]string{}, names[i:]...), path)
+			return nil, fmt.Errorf("include cycle: %s", strings.Join(cycle, " -> "))
+		}
+	}
+	f, err := readSpecFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if name := duplicateSpecFlag(f); name != "" {
+		return nil, fmt.Errorf("invalid spec file %s: flag %s is defined twice", path, name)
+	}
+	if len(f.Include) == 0 {
+		return f, nil
+	}
+	chain, names = append(chain, abs), append(names, path)
+	m := newSpecMerger()
+	for _, include := range f.Include {
+		if !filepath.IsAbs(include) {
+			// includes are relative to the including file
+			include = filepath.Join(filepath.Dir(path), include)
+		}
+		included, err := loadSpecFileIncludes(include, chain, names)
+		if err != nil {
+			return nil, err
+		}
+		if err := m.include(included, include); err != nil {
+			return nil, fmt.Errorf("invalid spec file %s: %w", path, err)
+		}
+	}
+	m.override(f)
+	return m.f, nil
+}
+
+// LoadSpecFile reads the spec file at path and merges the spec files it includes, in order,
+// before its own options, which override the included ones field by field.
+func LoadSpecFile(path string) (*SpecFile, error) {
+	return loadSpecFileIncludes(path, nil, nil)
+}
@@ -0,0 +1,103 @@
+package bind
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func writeSpecFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestLoadSpecFileIncludes(t *testing.T) {
+	dir := writeSpecFiles(t, map[string]string{
+		"common/base.yaml": "shell-type: sh\nflags:\n  - name: verbose\n    type: count\n    short: v\n  - name: env\n    default: dev\n    choices: [dev, prod]\n",
+		"common/run.yaml":  "include: [base.yaml]\nflags:\n  - name: dry-run\n    type: bool\n",
+		"other.yaml":       "include: [common/base.yaml]\nflags:\n  - name: force\n    type: bool\n",
+		"script.yaml": "include:\n  - common/run.yaml\n  - other.yaml\nname: deploy\nshell-type: csh\n" +
+			"flags:\n  - name: env\n    default: prod\n  - name: target\n",
+	})
+	f, err := LoadSpecFile(filepath.Join(dir, "script.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	args, err := RenderSpecArgs(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"--name=deploy", "--shell-type=csh",
+		"--flag=verbose", "--flag-verbose-short=v", "--flag-verbose-type=count",
+		"--flag=env", "--flag-env-default=prod", "--flag-env-choices=dev", "--flag-env-choices=prod",
+		"--flag=dry-run", "--flag-dry-run-type=bool",
+		"--flag=force", "--flag-force-type=bool",
+		"--flag=target",
+	}
+	if !reflect.DeepEqual(args, want) {
+		t.Fatalf("got %q want %q", args, want)
+	}
+}
+
+func TestLoadSpecFileIncludeErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+		want  string
+	}{
+		{"cycle", map[string]string{
+			"script.yaml": "include: [a.yaml]\n",
+			"a.yaml":      "include: [sub/b.yaml]\n",
+			"sub/b.yaml":  "include: [../a.yaml]\n",
+		}, "include cycle: DIR/a.yaml -> DIR/sub/b.yaml -> DIR/a.yaml"},
+		{"self", map[string]string{
+			"script.yaml": "include: [script.yaml]\n",
+		}, "include cycle: DIR/script.yaml -> DIR/script.yaml"},
+		{"conflicting_option", map[string]string{
+			"script.yaml": "include: [a.yaml, b.yaml]\n",
+			"a.yaml":      "shell-type: sh\n",
+			"b.yaml":      "shell-type: powershell\n",
+		}, "invalid spec file DIR/script.yaml: conflicting definitions of shell-type in DIR/a.yaml and DIR/b.yaml"},
+		{"conflicting_flag", map[string]string{
+			"script.yaml": "include: [a.yaml, b.yaml]\n",
+			"a.yaml":      "flags:\n  - name: env\n    default: dev\n",
+			"b.yaml":      "flags:\n  - name: env\n    default: prod\n",
+		}, "invalid spec file DIR/script.yaml: conflicting definitions of flag env in DIR/a.yaml and DIR/b.yaml"},
+		{"duplicate_flag", map[string]string{
+			"script.yaml": "include: [a.yaml]\n",
+			"a.yaml":      "flags:\n  - name: env\n  - name: env\n",
+		}, "invalid spec file DIR/a.yaml: flag env is defined twice"},
+		{"missing", map[string]string{
+			"script.yaml": "include: [a.yaml]\n",
+		}, "failed to read spec file: open DIR/a.yaml: no such file or directory"},
+		{"invalid_include", map[string]string{
+			"script.yaml": "include: [a.yaml]\n",
+			"a.yaml":      "flags:\n  - name: env\n    sort: random\n",
+		}, `invalid spec file DIR/a.yaml: flags[0].sort (line 3, column 11): value "random" is not one of none, asc, desc`},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := writeSpecFiles(t, tc.files)
+			_, err := LoadSpecFile(filepath.Join(dir, "script.yaml"))
+			if err == nil {
+				t.Fatalf("want error %q", tc.want)
+			}
+			if got := strings.ReplaceAll(err.Error(), dir, "DIR"); got != tc.want {
+				t.Fatalf("got %q want %q", got, tc.want)
+			}
+		})
+	}
+}
@@ -1,37 +1,96 @@
 //go:generate go run github.com/dmarkham/enumer -type=ShellType -trimprefix=ShellType -transform=kebab
+//go:generate go run github.com/dmarkham/enumer -type=FlagType -trimprefix=FlagType -transform=kebab
+//go:generate go run github.com/dmarkham/enumer -type=Scope -trimprefix=Scope -transform=kebab
+//go:generate go run github.com/dmarkham/enumer -type=ValueSource -trimprefix=ValueSource -transform=kebab
 //go:generate go run github.com/dmarkham/enumer -type=HelpSinkType -trimprefix=HelpSink -transform=kebab
 package bind
 
 type FlagSpec struct {
 	ShortName     string
+	Type          FlagType
 	Default       []string
 	NoOptDefValue string
 	Choices       []string
 	Required      bool
 	Multi         bool
 	MultiFormat   []string
+	MultiPreserve bool
+	Unique        string
+	Sort          string
+	MaxItems      int
+	MapDuplicate  string
+	MapKeys       []string
+	MapOutput     string
+	PathChecks    []string
+	PathNormalize []string
 	Helper        string
 	EnvName       string
-	Export        bool
+	VarName       string
+	Scope         Scope
+	UnsetMissing  bool
+	Readonly      bool
 	Value         []string
+	Missing       bool
+	Source        ValueSource
 }
 
 type CmdSpec struct {
-	Name        string
-	ShortDesc   string
-	LongDesc    string
-	Interactive bool
-	EnvPrefix   string
-	Flags       map[string]*FlagSpec
-	Debug       bool
-	ArgsRange   IntRange
-	ArgsChoices [][]string
-	ArgsValue   []string
-	ShellType   ShellType
-	HelpVar     string
-	HelpExport  bool
+	Name                string
+	ShortDesc           string
+	LongDesc            string
+	Interactive         bool
+	EnvPrefix           string
+	Flags               map[string]*FlagSpec
+	Debug               bool
+	ArgsRange           IntRange
+	ArgsChoices         [][]string
+	ArgsValue           []string
+	ShellType           ShellType
+	HelpVar             string
+	HelpScope           Scope
+	CmdScript           string
+	CmdDelayedExpansion bool
+	UnsetMissing        bool
+	ShDeclare           string
+	ShPersist           string
+	Output              string
+	OutputFile          string
+	Explain             bool
 }
 
+type FlagType int
+
+const (
+	FlagTypeStr FlagType = iota
+	FlagTypeBool
+	FlagTypeCount
+	FlagTypeMap
+	FlagTypeFile
+	FlagTypeDir
+	FlagTypePath
+)
+
+// Scope is where the variable of a flag is visible: only in the calling shell, in the environment
+// inherited by child processes, or persisted for the user and set in the session as well.
+type Scope int
+
+const (
+	ScopeShell Scope = iota
+	ScopeEnv
+	ScopeUserPersistent
+)
+
+// ValueSource is where the value of a flag came from: the command line, the empty value of a flag
+// given without a value, the default, or nowhere for an omitted flag without default.
+type ValueSource int
+
+const (
+	ValueSourceCli ValueSource = iota
+	ValueSourceEmptyValue
+	ValueSourceDefault
+	ValueSourceMissing
+)
+
 type ShellType int
 
 const (
@@ -39,6 +98,10 @@ const (
 	ShellTypeSh
 	ShellTypePowershell
 	ShellTypeCmd
+	ShellTypeNushell
+	ShellTypeElvish
+	ShellTypeXonsh
+	ShellTypeCsh
 )
 
 type ShellInfo struct {
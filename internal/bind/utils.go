@@ -1,9 +1,11 @@
 package bind
 
 import (
+	"encoding/csv"
 	"encoding/json"
 	"fmt"
 	"slices"
+	"sort"
 	"strings"
 
 	"github.com/spf13/pflag"
@@ -21,7 +23,92 @@ func splitAndTrim(s string, seps string) []string {
 	return parts
 }
 
-func ParseMultiValues(formats []string, rawValues []string, flag string) ([]string, error) {
+// splitPreserve splits s at every separator without trimming, so empty items are kept.
+// "\r\n" is treated as a single separator when newline is a separator.
+func splitPreserve(s string, seps string) []string {
+	if strings.ContainsRune(seps, '\n') {
+		s = strings.ReplaceAll(s, "\r\n", "\n")
+	}
+	isSep := func(r rune) bool { return strings.ContainsRune(seps, r) }
+	var parts []string
+	start := 0
+	for i, r := range s {
+		if isSep(r) {
+			parts = append(parts, s[start:i])
+			start = i + len(string(r))
+		}
+	}
+	return append(parts, s[start:])
+}
+
+// stringSeparator returns the separator of the nul and sep:<string> multi formats.
+func stringSeparator(format string) (string, bool) {
+	if format == "nul" {
+		return "\x00", true
+	}
+	if isSepMultiFormat(format) {
+		return strings.TrimPrefix(format, sepMultiFormatPrefix), true
+	}
+	return "", false
+}
+
+// splitBySeparator splits s at every sep. Unless preserve is set, empty items are dropped
+// and, if trim is set, the items are trimmed.
+func splitBySeparator(s string, sep string, preserve bool, trim bool) []string {
+	parts := strings.Split(s, sep)
+	if preserve {
+		return parts
+	}
+	result := parts[:0]
+	for _, part := range parts {
+		if trim {
+			part = strings.TrimSpace(part)
+		}
+		if part != "" {
+			result = append(result, part)
+		}
+	}
+	return result
+}
+
+// parseCsvValues parses raw as RFC 4180 csv, the fields of all records are returned in order.
+// Like encoding/csv, a "\r\n" inside a quoted field is read as "\n".
+func parseCsvValues(raw string, flag string) ([]string, error) {
+	r := csv.NewReader(strings.NewReader(raw))
+	r.FieldsPerRecord = -1
+	records, err := r.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("invalid csv multi value: %s for flag %s, %v", raw, flag, err)
+	}
+	var result []string
+	for _, record := range records {
+		result = append(result, record...)
+	}
+	return result, nil
+}
+
+// outputCsvValues joins values as a single RFC 4180 csv record.
+func outputCsvValues(values []string) (string, error) {
+	if len(values) == 1 && values[0] == "" {
+		// an empty record would be read back as no item at all
+		return `""`, nil
+	}
+	var buf strings.Builder
+	w := csv.NewWriter(&buf)
+	if err := w.Write(values); err != nil {
+		return "", fmt.Errorf("failed to write multi values as csv: %w", err)
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return "", fmt.Errorf("failed to write multi values as csv: %w", err)
+	}
+	return strings.TrimSuffix(buf.String(), "\n"), nil
+}
+
+// ParseMultiValues splits the raw values of a multi-valued flag according to formats.
+// Unless preserve is set, the comma, newline, space and sep:<string> formats trim the items and drop the empty ones,
+// the nul format only drops the empty ones, e.g. the trailing one of "find -print0".
+func ParseMultiValues(formats []string, preserve bool, rawValues []string, flag string) ([]string, error) {
 	if rawValues == nil {
 		return nil, nil
 	}
@@ -57,6 +144,28 @@ func ParseMultiValues(formats []string, rawValues []string, flag string) ([]stri
 			}
 		}
 		return result, nil
+	} else if checkInStringSlice("csv", formats) {
+		if len(formats) > 1 {
+			return nil, fmt.Errorf("multi format 'csv' for flag %s cannot be combined with other formats", flag)
+		}
+		var result []string
+		for _, raw := range rawValues {
+			values, err := parseCsvValues(raw, flag)
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, values...)
+		}
+		return result, nil
+	} else if sep, ok := stringSeparator(formats[0]); ok {
+		if len(formats) > 1 {
+			return nil, fmt.Errorf("multi format '%s' for flag %s cannot be combined with other formats", formats[0], flag)
+		}
+		var result []string
+		for _, raw := range rawValues {
+			result = append(result, splitBySeparator(raw, sep, preserve, formats[0] != "nul")...)
+		}
+		return result, nil
 	} else {
 		sepsBuilder := strings.Builder{}
 		for _, format := range formats {
@@ -74,13 +183,77 @@ func ParseMultiValues(formats []string, rawValues []string, flag string) ([]stri
 		seps := sepsBuilder.String()
 		var result []string
 		for _, raw := range rawValues {
-			splitValues := splitAndTrim(raw, seps)
+			var splitValues []string
+			if preserve {
+				splitValues = splitPreserve(raw, seps)
+			} else {
+				splitValues = splitAndTrim(raw, seps)
+			}
 			result = append(result, splitValues...)
 		}
 		return result, nil
 	}
 }
 
+var AllowedUniquePolicies = []string{"allow", "error", "dedup"}
+
+var AllowedSortOrders = []string{"none", "asc", "desc"}
+
+func checkMultiPolicy(unique string, sortOrder string, maxItems int, flag string) error {
+	if !checkInStringSlice(unique, AllowedUniquePolicies) {
+		return fmt.Errorf("invalid unique policy: %s for flag %s, allowed policies are: %v", unique, flag, AllowedUniquePolicies)
+	}
+	if !checkInStringSlice(sortOrder, AllowedSortOrders) {
+		return fmt.Errorf("invalid sort order: %s for flag %s, allowed orders are: %v", sortOrder, flag, AllowedSortOrders)
+	}
+	if maxItems < 0 {
+		return fmt.Errorf("invalid max items: %d for flag %s, it should not be negative", maxItems, flag)
+	}
+	return nil
+}
+
+// UniqueMultiValues applies the unique policy to the values of a multi-valued flag:
+// "allow" keeps duplicates, "error" rejects them and "dedup" keeps the first occurrence.
+func UniqueMultiValues(unique string, values []string, flag string) ([]string, error) {
+	if unique == "allow" || unique == "" || values == nil {
+		return values, nil
+	}
+	seen := make(map[string]struct{}, len(values))
+	result := make([]string, 0, len(values))
+	for _, value := range values {
+		if _, ok := seen[value]; ok {
+			if unique == "error" {
+				return nil, fmt.Errorf("duplicate value %s for flag %s", value, flag)
+			}
+			continue
+		}
+		seen[value] = struct{}{}
+		result = append(result, value)
+	}
+	return result, nil
+}
+
+// CanonicalizeMultiValues applies the unique policy, then the sort order, then the max items limit
+// to the values of a multi-valued flag. A max items of 0 means unlimited.
+func CanonicalizeMultiValues(unique string, sortOrder string, maxItems int, values []string, flag string) ([]string, error) {
+	values, err := UniqueMultiValues(unique, values, flag)
+	if err != nil {
+		return nil, err
+	}
+	switch sortOrder {
+	case "asc":
+		values = slices.Clone(values)
+		sort.Strings(values)
+	case "desc":
+		values = slices.Clone(values)
+		sort.Sort(sort.Reverse(sort.StringSlice(values)))
+	}
+	if maxItems > 0 && len(values) > maxItems {
+		return nil, fmt.Errorf("flag %s accepts at most %d values, but got %d", flag, maxItems, len(values))
+	}
+	return values, nil
+}
+
 func OutputMultiValues(formats []string, values []string) (string, error) {
 	isJson := false
 	if checkInStringSlice("json", formats) {
@@ -101,6 +274,18 @@ func OutputMultiValues(formats []string, values []string) (string, error) {
 	if len(formats) == 0 {
 		return strings.Join(values, ","), nil
 	}
+	if checkInStringSlice("csv", formats) {
+		if len(formats) > 1 {
+			return "", fmt.Errorf("multi format 'csv' cannot be combined with other formats")
+		}
+		return outputCsvValues(values)
+	}
+	if sep, ok := stringSeparator(formats[0]); ok {
+		if len(formats) > 1 {
+			return "", fmt.Errorf("multi format '%s' cannot be combined with other formats", formats[0])
+		}
+		return strings.Join(values, sep), nil
+	}
 	if isJson {
 		data, err := json.Marshal(values)
 		if err != nil {
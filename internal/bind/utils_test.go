@@ -0,0 +1,171 @@
+package bind
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCanonicalizeMultiValues(t *testing.T) {
+	tests := []struct {
+		name     string
+		unique   string
+		sort     string
+		maxItems int
+		in       []string
+		want     []string
+		wantErr  bool
+	}{
+		{"nil", "dedup", "asc", 1, nil, nil, false},
+		{"allow", "allow", "none", 0, []string{"b", "a", "b"}, []string{"b", "a", "b"}, false},
+		{"dedup_keep_first", "dedup", "none", 0, []string{"b", "a", "b"}, []string{"b", "a"}, false},
+		{"error", "error", "none", 0, []string{"b", "a", "b"}, nil, true},
+		{"asc", "allow", "asc", 0, []string{"b", "c", "a"}, []string{"a", "b", "c"}, false},
+		{"desc", "allow", "desc", 0, []string{"b", "c", "a"}, []string{"c", "b", "a"}, false},
+		{"max_items_after_dedup", "dedup", "none", 2, []string{"a", "a", "b"}, []string{"a", "b"}, false},
+		{"max_items_exceeded", "allow", "none", 2, []string{"a", "a", "b"}, nil, true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := CanonicalizeMultiValues(tc.unique, tc.sort, tc.maxItems, tc.in, "flag")
+			if tc.wantErr {
+				if err == nil {
+					t.Fatalf("expected error for %v, got %v", tc.in, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error for %v: %v", tc.in, err)
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Fatalf("got %#v want %#v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestParseMultiValues(t *testing.T) {
+	tests := []struct {
+		name     string
+		formats  []string
+		preserve bool
+		in       []string
+		want     []string
+		wantErr  bool
+	}{
+		{"comma_trim", []string{"comma"}, false, []string{" a,,b ", "c"}, []string{"a", "b", "c"}, false},
+		{"comma_preserve", []string{"comma"}, true, []string{" a, ,b ", "c"}, []string{" a", " ", "b ", "c"}, false},
+		{"comma_preserve_empty", []string{"comma"}, true, []string{"", "a,"}, []string{"", "a", ""}, false},
+		{"newline_preserve_crlf", []string{"newline"}, true, []string{"a\r\n\nb"}, []string{"a", "", "b"}, false},
+		{"csv", []string{"csv"}, false, []string{`a,"b,c", d ,`}, []string{"a", "b,c", " d ", ""}, false},
+		{"csv_quote", []string{"csv"}, false, []string{`"say ""hi"""`}, []string{`say "hi"`}, false},
+		{"csv_multiline", []string{"csv"}, false, []string{"\"a\nb\",c\nd"}, []string{"a\nb", "c", "d"}, false},
+		{"csv_repeated", []string{"csv"}, false, []string{"a,b", "c"}, []string{"a", "b", "c"}, false},
+		{"csv_empty_item", []string{"csv"}, false, []string{`""`}, []string{""}, false},
+		{"csv_invalid", []string{"csv"}, false, []string{`a"b`}, nil, true},
+		{"csv_combined", []string{"csv", "comma"}, false, []string{"a"}, nil, true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := ParseMultiValues(tc.formats, tc.preserve, tc.in, "flag")
+			if tc.wantErr {
+				if err == nil {
+					t.Fatalf("expected error for %q, got %q", tc.in, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error for %q: %v", tc.in, err)
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Fatalf("got %q want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestMultiValuesRoundTrip(t *testing.T) {
+	tests := []struct {
+		name    string
+		formats []string
+		values  []string
+	}{
+		{"csv_plain", []string{"csv"}, []string{"a", "b"}},
+		{"csv_comma", []string{"csv"}, []string{"a,b", "c"}},
+		{"csv_quote", []string{"csv"}, []string{`"quoted"`, `it's`}},
+		{"csv_space", []string{"csv"}, []string{" leading", "trailing ", " "}},
+		{"csv_empty_items", []string{"csv"}, []string{"", "a", ""}},
+		{"csv_single_empty", []string{"csv"}, []string{""}},
+		{"csv_newline", []string{"csv"}, []string{"a\nb", "c"}},
+		{"json", []string{"json"}, []string{"a,b", "", "c\nd"}},
+		{"nul", []string{"nul"}, []string{"a b", "c\nd", "e,f"}},
+		{"sep", []string{"sep::"}, []string{"/bin", "/usr/local/bin"}},
+		{"sep_multi_char", []string{"sep:, "}, []string{"a,b", "c d"}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			out, err := OutputMultiValues(tc.formats, tc.values)
+			if err != nil {
+				t.Fatalf("output %q: %v", tc.values, err)
+			}
+			got, err := ParseMultiValues(tc.formats, false, []string{out}, "flag")
+			if err != nil {
+				t.Fatalf("parse %q: %v", out, err)
+			}
+			if !reflect.DeepEqual(got, tc.values) {
+				t.Fatalf("round trip through %q: got %q want %q", out, got, tc.values)
+			}
+		})
+	}
+}
+
+func TestSeparatorMultiFormats(t *testing.T) {
+	tests := []struct {
+		name     string
+		formats  []string
+		preserve bool
+		in       []string
+		want     []string
+	}{
+		{"nul", []string{"nul"}, false, []string{"a b\x00c\nd\x00"}, []string{"a b", "c\nd"}},
+		{"nul_preserve", []string{"nul"}, true, []string{"a\x00\x00b\x00"}, []string{"a", "", "b", ""}},
+		{"sep_colon", []string{"sep::"}, false, []string{"/bin: /usr/bin::"}, []string{"/bin", "/usr/bin"}},
+		{"sep_multi_char", []string{"sep:||"}, false, []string{"a|b||c"}, []string{"a|b", "c"}},
+		{"sep_preserve", []string{"sep::"}, true, []string{":a: "}, []string{"", "a", " "}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := ParseMultiValues(tc.formats, tc.preserve, tc.in, "flag")
+			if err != nil {
+				t.Fatalf("unexpected error for %q: %v", tc.in, err)
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Fatalf("got %q want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestCheckMultiFormat(t *testing.T) {
+	tests := []struct {
+		formats []string
+		wantErr bool
+	}{
+		{[]string{"comma", "space"}, false},
+		{[]string{"nul"}, false},
+		{[]string{"sep::"}, false},
+		{[]string{"sep:"}, true},
+		{[]string{"nul", "comma"}, true},
+		{[]string{"comma", "sep::"}, true},
+		{[]string{"csv", "json"}, true},
+		{[]string{"tab"}, true},
+	}
+	for _, tc := range tests {
+		err := checkMultiFormat(tc.formats, "flag")
+		if tc.wantErr && err == nil {
+			t.Fatalf("checkMultiFormat(%q): expected error", tc.formats)
+		}
+		if !tc.wantErr && err != nil {
+			t.Fatalf("checkMultiFormat(%q): unexpected error %v", tc.formats, err)
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package bind
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// buildPythonLiteral renders s as a single-quoted Python string, escaping the backslash,
+// the single quote and the control characters. xonsh environment values are str, so a
+// value which is not valid UTF-8 is rejected.
+func buildPythonLiteral(s string) (string, error) {
+	if !utf8.ValidString(s) {
+		return "", fmt.Errorf("it is not valid UTF-8, which xonsh cannot hold")
+	}
+	var b strings.Builder
+	b.WriteByte('\'')
+	for _, r := range s {
+		switch r {
+		case '\\':
+			b.WriteString(`\\`)
+		case '\'':
+			b.WriteString(`\'`)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\r':
+			b.WriteString(`\r`)
+		case '\t':
+			b.WriteString(`\t`)
+		default:
+			if r < 0x20 || r == 0x7f {
+				fmt.Fprintf(&b, `\x%02x`, r)
+			} else {
+				b.WriteRune(r)
+			}
+		}
+	}
+	b.WriteByte('\'')
+	return b.String(), nil
+}
+
+// exportEnvVarXonsh renders a $NAME assignment of the xonsh environment, which is used for
+// the shell and env scopes alike.
+func exportEnvVarXonsh(varName string, val string) (string, error) {
+	literal, err := buildPythonLiteral(val)
+	if err != nil {
+		return "", fmt.Errorf("value of %s: %w", varName, err)
+	}
+	return fmt.Sprintf("$%s = %s", varName, literal), nil
+}
+
+// unsetEnvVarXonsh removes the variable without failing when it is not set, unlike del $NAME.
+func unsetEnvVarXonsh(varName string) string {
+	return fmt.Sprintf("${...}.pop('%s', None)", varName)
+}
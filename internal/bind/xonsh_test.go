@@ -0,0 +1,55 @@
+package bind
+
+import (
+	"os/exec"
+	"testing"
+)
+
+func TestBuildPythonLiteral(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"empty", "", "''"},
+		{"plain", `a "b" $c`, `'a "b" $c'`},
+		{"escapes", "it's \\ a\r\n\tb", `'it\'s \\ a\r\n\tb'`},
+		{"control", "\x1b[0m\x7f", `'\x1b[0m\x7f'`},
+		{"unicode", "用户", "'用户'"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := buildPythonLiteral(tc.in)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tc.want {
+				t.Fatalf("got %q want %q", got, tc.want)
+			}
+		})
+	}
+	if _, err := buildPythonLiteral("\xff"); err == nil {
+		t.Fatalf("expected error for invalid UTF-8")
+	}
+}
+
+// TestBuildPythonLiteralRoundTrip evaluates the literals with python, which parses them like xonsh.
+func TestBuildPythonLiteralRoundTrip(t *testing.T) {
+	python, err := exec.LookPath("python3")
+	if err != nil {
+		t.Skip("python3 not found")
+	}
+	for _, val := range []string{"", "it's", `back\slash\`, "a\r\nb\t", "\x01\x1f\x7f", "用户-\U0001F680", `"$HOME" {x}`} {
+		literal, err := buildPythonLiteral(val)
+		if err != nil {
+			t.Fatalf("unexpected error for %q: %v", val, err)
+		}
+		out, err := exec.Command(python, "-c", "import sys; sys.stdout.buffer.write(("+literal+").encode())").Output()
+		if err != nil {
+			t.Fatalf("python: run %q: %v", literal, err)
+		}
+		if string(out) != val {
+			t.Fatalf("%q evaluated to %q, want %q", literal, out, val)
+		}
+	}
+}
@@ -0,0 +1,67 @@
+// Package shell renders string literals and variable assignments for the shells supported by argonaut,
+// and detects the shell argonaut runs in.
+package shell
+
+import (
+	"github.com/vipcxj/argonaut/internal/bind"
+)
+
+// Type is a shell type, sh covers the sh-like shells such as bash, zsh, dash and ksh.
+type Type = bind.ShellType
+
+const (
+	Auto       Type = bind.ShellTypeAuto
+	Sh         Type = bind.ShellTypeSh
+	Powershell Type = bind.ShellTypePowershell
+	Cmd        Type = bind.ShellTypeCmd
+	Nushell    Type = bind.ShellTypeNushell
+	Elvish     Type = bind.ShellTypeElvish
+	Xonsh      Type = bind.ShellTypeXonsh
+	Csh        Type = bind.ShellTypeCsh
+)
+
+// Scope is where an assigned variable is visible.
+type Scope = bind.Scope
+
+const (
+	// ScopeShell is only visible in the calling shell
+	ScopeShell Scope = bind.ScopeShell
+	// ScopeEnv is inherited by child processes
+	ScopeEnv Scope = bind.ScopeEnv
+	// ScopeUserPersistent is persisted for the user, and set in the session as well
+	ScopeUserPersistent Scope = bind.ScopeUserPersistent
+)
+
+// Detection is the detected shell, how it was decided and the parent processes which were looked at.
+type Detection = bind.ShellDetection
+
+// ParseType parses a shell type name such as "sh" or "powershell".
+func ParseType(s string) (Type, error) {
+	return bind.ShellTypeString(s)
+}
+
+// Types returns all shell types, including Auto.
+func Types() []Type {
+	return bind.ShellTypeValues()
+}
+
+// ParseScope parses a scope name: shell, env or user-persistent.
+func ParseScope(s string) (Scope, error) {
+	return bind.ScopeString(s)
+}
+
+// Detect detects the shell like --shell-type=auto does: $ARGONAUT_SHELL, the parent processes, then $SHELL or $COMSPEC.
+func Detect() (*Detection, error) {
+	return bind.DetectShell(bind.OSEnv())
+}
+
+// Quote quotes s as a string literal of the shell type, e.g. in single quotes for sh.
+func Quote(t Type, s string) (string, error) {
+	return bind.QuoteLiteral(t, s)
+}
+
+// Export returns the statements setting the variable name to value in the scope, e.g. export NAME='value' for sh
+// and scope env. For sh-like shells the user-persistent scope is only exported in the session.
+func Export(t Type, name string, value string, scope Scope) ([]string, error) {
+	return bind.ExportVar(t, name, value, scope)
+}
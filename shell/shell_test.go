@@ -0,0 +1,64 @@
+package shell
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestQuote(t *testing.T) {
+	tests := []struct {
+		shellType Type
+		value     string
+		want      string
+	}{
+		{Sh, "it's", `'it'\''s'`},
+		{Powershell, "it's", `'it''s'`},
+		{Csh, "a!b", `'a'\!'b'`},
+		{Nushell, "it's", `r#'it's'#`},
+	}
+	for _, tc := range tests {
+		got, err := Quote(tc.shellType, tc.value)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != tc.want {
+			t.Fatalf("%s: got %q want %q", tc.shellType, got, tc.want)
+		}
+	}
+	if _, err := Quote(Auto, "a"); err == nil {
+		t.Fatalf("want error for shell type auto")
+	}
+}
+
+func TestExport(t *testing.T) {
+	tests := []struct {
+		shellType Type
+		scope     Scope
+		want      []string
+	}{
+		{Sh, ScopeShell, []string{"NAME='v'"}},
+		{Sh, ScopeEnv, []string{"export NAME='v'"}},
+		{Powershell, ScopeEnv, []string{"$Env:NAME = 'v'"}},
+		{Elvish, ScopeEnv, []string{"set-env NAME 'v'"}},
+		{Csh, ScopeEnv, []string{"setenv NAME 'v'"}},
+	}
+	for _, tc := range tests {
+		got, err := Export(tc.shellType, "NAME", "v", tc.scope)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Fatalf("%s: got %q want %q", tc.shellType, got, tc.want)
+		}
+	}
+}
+
+func TestParseType(t *testing.T) {
+	got, err := ParseType("powershell")
+	if err != nil || got != Powershell {
+		t.Fatalf("got %v, %v", got, err)
+	}
+	if _, err := ParseType("fish"); err == nil {
+		t.Fatalf("want error for an unknown shell type")
+	}
+}
@@ -0,0 +1,223 @@
+package spec
+
+// Builder defines a spec in Go, e.g. spec.New("deploy").Flag("env", spec.Default("dev")).Build().
+type Builder struct {
+	spec Spec
+}
+
+// New starts a spec of the command name.
+func New(name string) *Builder {
+	return &Builder{spec: Spec{Name: &name}}
+}
+
+// ShortDesc sets the short description of the command, like --short.
+func (b *Builder) ShortDesc(s string) *Builder {
+	b.spec.Short = &s
+	return b
+}
+
+// LongDesc sets the long description of the command, like --long.
+func (b *Builder) LongDesc(s string) *Builder {
+	b.spec.Long = &s
+	return b
+}
+
+// EnvPrefix sets the prefix of the variable names, like --env-prefix.
+func (b *Builder) EnvPrefix(prefix string) *Builder {
+	b.spec.EnvPrefix = &prefix
+	return b
+}
+
+// ShellType sets the shell type of the spec, like --shell-type. The results can be rendered for other shell types too.
+func (b *Builder) ShellType(t string) *Builder {
+	b.spec.ShellType = &t
+	return b
+}
+
+// Output sets the output, like --output, e.g. dotenv.
+func (b *Builder) Output(output string) *Builder {
+	b.spec.Output = &output
+	return b
+}
+
+// ArgsRange sets the range of the number of positional arguments, like --args-range, e.g. [1,3].
+func (b *Builder) ArgsRange(r string) *Builder {
+	b.spec.ArgsRange = &r
+	return b
+}
+
+// HelpVar sets the variable indicating a help request, like --help-var.
+func (b *Builder) HelpVar(name string) *Builder {
+	b.spec.HelpVar = &name
+	return b
+}
+
+// HelpScope sets the scope of the help variable, like --help-scope.
+func (b *Builder) HelpScope(scope string) *Builder {
+	b.spec.HelpScope = &scope
+	return b
+}
+
+// UnsetMissing unsets the variables of all omitted flags without a default, like --unset-missing.
+func (b *Builder) UnsetMissing() *Builder {
+	unset := true
+	b.spec.UnsetMissing = &unset
+	return b
+}
+
+// ShDeclare sets how sh-like shells declare the variables, like --sh-declare, e.g. local.
+func (b *Builder) ShDeclare(declare string) *Builder {
+	b.spec.ShDeclare = &declare
+	return b
+}
+
+// Flag adds the flag name with the options.
+func (b *Builder) Flag(name string, options ...FlagOption) *Builder {
+	f := Flag{Name: name}
+	for _, option := range options {
+		option(&f)
+	}
+	b.spec.Flags = append(b.spec.Flags, f)
+	return b
+}
+
+// Spec returns the spec defined so far, e.g. to write it as a spec file with Marshal.
+func (b *Builder) Spec() *Spec {
+	s := b.spec
+	s.Flags = append([]Flag(nil), b.spec.Flags...)
+	return &s
+}
+
+// Build checks the spec like bind does, with the environment variables of the process.
+func (b *Builder) Build() (*Command, error) {
+	return FromSpec(b.Spec())
+}
+
+// BuildEnv checks the spec like Build, the environment variables are looked up by lookupEnv instead
+// of the process, see FromSpecEnv.
+func (b *Builder) BuildEnv(lookupEnv func(key string) (string, bool)) (*Command, error) {
+	return FromSpecEnv(b.Spec(), lookupEnv)
+}
+
+// FlagOption sets an option of a flag, like a --flag-<name>-* option of bind.
+type FlagOption func(f *Flag)
+
+// ShortName sets the one letter short name of the flag.
+func ShortName(name string) FlagOption {
+	return func(f *Flag) {
+		f.Short = &name
+	}
+}
+
+// Helper sets the help text of the flag.
+func Helper(text string) FlagOption {
+	return func(f *Flag) {
+		f.Helper = &text
+	}
+}
+
+// Type sets the type of the flag: str, bool, count, map, file, dir or path.
+func Type(t string) FlagOption {
+	return func(f *Flag) {
+		f.Type = &t
+	}
+}
+
+// Multi makes the flag multi-valued, the values are output in the formats, comma if none is given.
+func Multi(formats ...string) FlagOption {
+	return func(f *Flag) {
+		multi := true
+		f.Multi = &multi
+		if len(formats) > 0 {
+			f.MultiFormat = formats
+		}
+	}
+}
+
+// Default sets the default values of the flag, used when it is omitted.
+func Default(values ...string) FlagOption {
+	return func(f *Flag) {
+		list := StringList(values)
+		f.Default = &list
+	}
+}
+
+// EmptyValue sets the value used when the flag is given without a value.
+func EmptyValue(value string) FlagOption {
+	return func(f *Flag) {
+		f.EmptyValue = &value
+	}
+}
+
+// Choices sets the allowed values of the flag.
+func Choices(values ...string) FlagOption {
+	return func(f *Flag) {
+		f.Choices = values
+	}
+}
+
+// Required makes the flag required, a default is used if it is omitted.
+func Required() FlagOption {
+	return func(f *Flag) {
+		required := true
+		f.Required = &required
+	}
+}
+
+// EnvName sets the variable name of the flag, which is not prefixed by the env prefix.
+func EnvName(name string) FlagOption {
+	return func(f *Flag) {
+		f.EnvName = &name
+	}
+}
+
+// Scope sets the scope of the variable of the flag: shell, env or user-persistent.
+func Scope(scope string) FlagOption {
+	return func(f *Flag) {
+		f.Scope = &scope
+	}
+}
+
+// Readonly declares the variable of the flag readonly for sh-like shells.
+func Readonly() FlagOption {
+	return func(f *Flag) {
+		readonly := true
+		f.Readonly = &readonly
+	}
+}
+
+// UnsetMissing unsets the variable of the flag when it is omitted and has no default.
+func UnsetMissing() FlagOption {
+	return func(f *Flag) {
+		unset := true
+		f.UnsetMissing = &unset
+	}
+}
+
+// MapKeys sets the allowed keys of a map flag.
+func MapKeys(keys ...string) FlagOption {
+	return func(f *Flag) {
+		f.MapKeys = keys
+	}
+}
+
+// MapOutput sets the output of a map flag: json, vars or assoc.
+func MapOutput(output string) FlagOption {
+	return func(f *Flag) {
+		f.MapOutput = &output
+	}
+}
+
+// PathChecks sets the checks of a file, dir or path flag, e.g. must-exist and readable.
+func PathChecks(checks ...string) FlagOption {
+	return func(f *Flag) {
+		f.PathChecks = checks
+	}
+}
+
+// PathNormalize sets the normalizations of a file, dir or path flag: home, abs and clean.
+func PathNormalize(normalizations ...string) FlagOption {
+	return func(f *Flag) {
+		f.PathNormalize = normalizations
+	}
+}
@@ -0,0 +1,209 @@
+// Package spec defines argonaut bind specs in Go, resolves them against command line arguments and
+// renders the variables for any shell type, the same way 'argonaut bind' does.
+//
+//	cmd, err := spec.New("deploy").
+//		Flag("env", spec.Default("dev"), spec.Choices("dev", "prod")).
+//		Flag("verbose", spec.Type("count"), spec.ShortName("v")).
+//		Build()
+//	result, err := cmd.Resolve([]string{"deploy", "--env=prod", "-vv"})
+//	env, _ := result.Value("env")
+//	out, err := result.Render(shell.Powershell)
+package spec
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+
+	"github.com/vipcxj/argonaut/internal/bind"
+	"github.com/vipcxj/argonaut/shell"
+)
+
+// Spec is a bind spec as written in a spec file, its fields mirror the options of bind and
+// a nil field means the option is not given.
+type Spec = bind.SpecFile
+
+// Flag is a flag of a Spec, its fields mirror the --flag-<name>-* options of bind.
+type Flag = bind.FlagFile
+
+// StringList is a list of values, written as a scalar in a spec file if it holds a single item.
+type StringList = bind.StringList
+
+// IntRange is a range of integers such as [1,3] or >2, used by --args-range.
+type IntRange = bind.IntRange
+
+// NaturalRangeFilter is a set of natural numbers such as 1_3-5_7-.
+type NaturalRangeFilter = bind.NaturalRangeFilter
+
+// ParseIntRange parses a range such as 1, >1, <=3, [1,3], (,5], [2,) or (,).
+func ParseIntRange(s string) (IntRange, error) {
+	return bind.NewIntRange(s, false)
+}
+
+// ParseNaturalRangeFilter parses a filter of natural numbers such as 1_3-5_7-.
+func ParseNaturalRangeFilter(s string) (NaturalRangeFilter, error) {
+	return bind.NewNaturalRangeFilter(s)
+}
+
+// Formats are the formats of spec files.
+var Formats = bind.AllowedSpecFormats
+
+// Marshal writes the spec in the format, yaml or json.
+func Marshal(s *Spec, format string) ([]byte, error) {
+	return bind.MarshalSpecFile(s, format)
+}
+
+// Unmarshal reads a spec in the format, yaml or json, and checks it against the schema. Includes are not resolved.
+func Unmarshal(data []byte, format string) (*Spec, error) {
+	return bind.UnmarshalSpecFile(data, format)
+}
+
+// Schema returns the JSON Schema of spec files.
+func Schema() ([]byte, error) {
+	return bind.MarshalSpecSchema()
+}
+
+// Command is a checked spec, ready to resolve command line arguments.
+type Command struct {
+	spec *Spec
+	args []string
+}
+
+// FromSpec checks the spec like bind does, with the environment variables of the process. Its includes
+// must be resolved, see Load.
+func FromSpec(s *Spec) (*Command, error) {
+	return FromSpecEnv(s, os.LookupEnv)
+}
+
+// FromSpecEnv checks the spec like FromSpec, the environment variables, e.g. $ARGONAUT_SHELL deciding
+// the shell type auto, are looked up by lookupEnv instead of the process.
+func FromSpecEnv(s *Spec, lookupEnv func(key string) (string, bool)) (*Command, error) {
+	args, err := bind.RenderSpecArgs(s)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := bind.ExportSpec(args, checkEnv(lookupEnv)); err != nil {
+		return nil, err
+	}
+	return &Command{spec: s, args: args}, nil
+}
+
+// FromArgs checks the bind options, as given to 'argonaut bind' before '--', with the environment
+// variables of the process.
+func FromArgs(bindArgs []string) (*Command, error) {
+	return FromArgsEnv(bindArgs, os.LookupEnv)
+}
+
+// FromArgsEnv checks the bind options like FromArgs, the environment variables are looked up by
+// lookupEnv instead of the process.
+func FromArgsEnv(bindArgs []string, lookupEnv func(key string) (string, bool)) (*Command, error) {
+	s, err := bind.ExportSpec(bindArgs, checkEnv(lookupEnv))
+	if err != nil {
+		return nil, err
+	}
+	return FromSpecEnv(s, lookupEnv)
+}
+
+// checkEnv is the Env of the checks of a spec, which print nothing.
+func checkEnv(lookupEnv func(key string) (string, bool)) *bind.Env {
+	return &bind.Env{Stdout: io.Discard, Stderr: io.Discard, LookupEnv: lookupEnv}
+}
+
+// Load reads the spec file at path, json if its extension is .json and yaml otherwise, with its includes.
+func Load(path string) (*Command, error) {
+	s, err := bind.LoadSpecFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return FromSpec(s)
+}
+
+// Spec returns the spec of the command.
+func (c *Command) Spec() *Spec {
+	return c.spec
+}
+
+// Args returns the bind options of the command.
+func (c *Command) Args() []string {
+	return slices.Clone(c.args)
+}
+
+// Resolve resolves argv against the command with the environment variables of the process, argv[0] is
+// the command name. A Command may resolve several argvs concurrently.
+func (c *Command) Resolve(argv []string) (*Result, error) {
+	return c.ResolveEnv(argv, os.LookupEnv)
+}
+
+// ResolveEnv resolves argv like Resolve, the environment variables, e.g. $ARGONAUT_SHELL, $HOME and
+// $GITHUB_ENV, are looked up by lookupEnv instead of the process.
+func (c *Command) ResolveEnv(argv []string, lookupEnv func(key string) (string, bool)) (*Result, error) {
+	var usage bytes.Buffer
+	r, err := bind.Resolve(c.args, argv, &bind.Env{Stdout: io.Discard, Stderr: &usage, LookupEnv: lookupEnv})
+	if err != nil {
+		return nil, err
+	}
+	return &Result{cmd: c, argv: slices.Clone(argv), lookupEnv: lookupEnv, resolution: r, Help: r.Help, Usage: usage.String(), Args: r.Args}, nil
+}
+
+// Result is the resolution of an argv.
+type Result struct {
+	cmd        *Command
+	argv       []string
+	lookupEnv  func(key string) (string, bool)
+	resolution *bind.Resolution
+	// Help is set if the help was requested, e.g. by -h
+	Help bool
+	// Usage is the help of the command if it was requested
+	Usage string
+	// Args are the positional arguments
+	Args []string
+}
+
+// Value returns the values of the flag, a single value unless it is multi-valued or a map flag,
+// whose values are key=value pairs. ok is false if the flag does not exist or has no value, i.e.
+// it is omitted and has no default.
+func (r *Result) Value(flag string) (values []string, ok bool) {
+	fs, exists := r.resolution.Spec.Flags[flag]
+	if !exists || fs.Missing {
+		return nil, false
+	}
+	return slices.Clone(fs.Value), true
+}
+
+// Values returns the values of all flags which have one.
+func (r *Result) Values() map[string][]string {
+	values := make(map[string][]string)
+	for name, fs := range r.resolution.Spec.Flags {
+		if !fs.Missing {
+			values[name] = slices.Clone(fs.Value)
+		}
+	}
+	return values
+}
+
+// Source returns where the value of the flag came from: cli, empty-value, default or missing.
+func (r *Result) Source(flag string) (string, error) {
+	fs, exists := r.resolution.Spec.Flags[flag]
+	if !exists {
+		return "", fmt.Errorf("unknown flag: %s", flag)
+	}
+	return fs.Source.String(), nil
+}
+
+// Render returns what 'argonaut bind' prints for the argv and shell type: the help variable if the help
+// was requested, otherwise the variables of the flags. shell.Auto uses the shell type of the spec.
+// Like bind, it persists the variables of --sh-persist and writes the github-env and github-output files.
+func (r *Result) Render(t shell.Type) (string, error) {
+	if t == shell.Auto || t == r.resolution.Spec.ShellType {
+		return r.resolution.Render()
+	}
+	// the variable names and default scopes depend on the shell type, so the argv is resolved again for it
+	args := append(slices.Clone(r.cmd.args), fmt.Sprintf("--shell-type=%s", t))
+	resolution, err := bind.Resolve(args, r.argv, &bind.Env{Stdout: io.Discard, Stderr: io.Discard, LookupEnv: r.lookupEnv})
+	if err != nil {
+		return "", err
+	}
+	return resolution.Render()
+}
@@ -0,0 +1,219 @@
+package spec
+
+import (
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/vipcxj/argonaut/shell"
+)
+
+func newDeployCommand(t *testing.T) *Command {
+	t.Helper()
+	cmd, err := New("deploy").
+		ShellType("sh").
+		ShortDesc("Deploy the app").
+		Flag("env", Default("dev"), Choices("dev", "prod"), ShortName("e")).
+		Flag("verbose", Type("count"), ShortName("v")).
+		Flag("tags", Multi()).
+		Flag("target").
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cmd
+}
+
+func TestBuilderResolve(t *testing.T) {
+	cmd := newDeployCommand(t)
+	r, err := cmd.Resolve([]string{"deploy", "-e", "prod", "-vv", "--tags=a,b", "app"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string][]string{"env": {"prod"}, "verbose": {"2"}, "tags": {"a", "b"}}
+	if got := r.Values(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %q want %q", got, want)
+	}
+	if _, ok := r.Value("target"); ok {
+		t.Fatalf("want no value of the omitted flag target")
+	}
+	if source, _ := r.Source("env"); source != "cli" {
+		t.Fatalf("got source %q want %q", source, "cli")
+	}
+	if !reflect.DeepEqual(r.Args, []string{"app"}) {
+		t.Fatalf("got args %q want %q", r.Args, []string{"app"})
+	}
+	tests := []struct {
+		shellType shell.Type
+		want      string
+	}{
+		{shell.Auto, "ENV='prod'\nTAGS='a,b'\nTARGET=''\nVERBOSE='2'"},
+		{shell.Powershell, "$Env:ENV = 'prod'\n$Env:TAGS = 'a,b'\n$Env:TARGET = ''\n$Env:VERBOSE = '2'"},
+		{shell.Csh, "set ENV = 'prod'\nset TAGS = 'a,b'\nset TARGET = ''\nset VERBOSE = '2'"},
+	}
+	for _, tc := range tests {
+		got, err := r.Render(tc.shellType)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != tc.want {
+			t.Fatalf("%s: got %q want %q", tc.shellType, got, tc.want)
+		}
+	}
+}
+
+func TestResolveHelpAndErrors(t *testing.T) {
+	cmd := newDeployCommand(t)
+	r, err := cmd.Resolve([]string{"deploy", "-h"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !r.Help || !strings.HasPrefix(r.Usage, "Deploy the app\n") {
+		t.Fatalf("got help %v usage %q", r.Help, r.Usage)
+	}
+	if out, err := r.Render(shell.Auto); err != nil || out != "IS_HELP='true'" {
+		t.Fatalf("got %q, %v want %q", out, err, "IS_HELP='true'")
+	}
+	if _, err := cmd.Resolve([]string{"deploy", "--env=test"}); err == nil || err.Error() != "value test for flag env is not in allowed choices [dev prod]" {
+		t.Fatalf("got error %v", err)
+	}
+	if _, err := New("deploy").Flag("env", Default("test"), Choices("dev")).Build(); err == nil {
+		t.Fatalf("want error for a default outside the choices")
+	}
+}
+
+func TestResolveConcurrently(t *testing.T) {
+	cmd := newDeployCommand(t)
+	var wg sync.WaitGroup
+	errs := make(chan error, 20)
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			env := []string{"dev", "prod"}[i%2]
+			r, err := cmd.Resolve([]string{"deploy", "--env=" + env})
+			if err != nil {
+				errs <- err
+				return
+			}
+			if got, _ := r.Value("env"); !reflect.DeepEqual(got, []string{env}) {
+				t.Errorf("got %q want %q", got, env)
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Fatal(err)
+	}
+}
+
+func TestResolveEnv(t *testing.T) {
+	cmd, err := New("deploy").Flag("env", EnvName("DEPLOY_ENV")).Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		shell string
+		want  string
+	}{
+		{"powershell", "$Env:DEPLOY_ENV = 'prod'"},
+		{"ssh", ""},
+		{"sh", "DEPLOY_ENV='prod'"},
+		{"elvish", "set-env DEPLOY_ENV 'prod'"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.shell, func(t *testing.T) {
+			t.Parallel()
+			lookupEnv := func(key string) (string, bool) {
+				if key == "ARGONAUT_SHELL" {
+					return tc.shell, true
+				}
+				return "", false
+			}
+			r, err := cmd.ResolveEnv([]string{"deploy", "--env=prod"}, lookupEnv)
+			if tc.want == "" {
+				if err == nil {
+					t.Fatalf("expected error for ARGONAUT_SHELL=%s", tc.shell)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			out, err := r.Render(shell.Auto)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if out != tc.want {
+				t.Fatalf("got %q want %q", out, tc.want)
+			}
+		})
+	}
+}
+
+func TestFromArgsRoundTrip(t *testing.T) {
+	cmd, err := FromArgs([]string{"--shell-type=sh", "--flag=name", "--flag-name-default=guest"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := Marshal(cmd.Spec(), "yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "shell-type: sh\nflags:\n  - name: name\n    default: guest\n"
+	if string(data) != want {
+		t.Fatalf("got %q want %q", data, want)
+	}
+}
+
+func TestParseRanges(t *testing.T) {
+	r, err := ParseIntRange("[1,3]")
+	if err != nil || !r.Contains(3) || r.Contains(4) {
+		t.Fatalf("got %v, %v", r, err)
+	}
+	f, err := ParseNaturalRangeFilter("1_3-5")
+	if err != nil || !f.Test(4) || f.Test(2) {
+		t.Fatalf("got %v, %v", f, err)
+	}
+}
+
+func TestBuildShortNames(t *testing.T) {
+	tests := []struct {
+		name    string
+		builder *Builder
+		want    string
+	}{
+		{"duplicate", New("deploy").Flag("a", ShortName("x")).Flag("b", ShortName("x")), "flag b: short name x is already used by flag a"},
+		{"help", New("deploy").Flag("host", ShortName("h")), "flag host: short name h collides with -h of the help flag, which then no longer shows the help"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := tc.builder.Build(); err == nil || err.Error() != tc.want {
+				t.Fatalf("got error %v want %q", err, tc.want)
+			}
+		})
+	}
+}
+
+func TestBuildEnv(t *testing.T) {
+	builder := New("deploy").Flag("env")
+	lookupEnv := func(shell string) func(key string) (string, bool) {
+		return func(key string) (string, bool) {
+			if key == "ARGONAUT_SHELL" {
+				return shell, true
+			}
+			return "", false
+		}
+	}
+	if _, err := builder.BuildEnv(lookupEnv("ssh")); err == nil {
+		t.Fatalf("expected error for ARGONAUT_SHELL=ssh")
+	}
+	if _, err := builder.BuildEnv(lookupEnv("powershell")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := FromArgsEnv([]string{"--flag=env"}, lookupEnv("elvish")); err != nil {
+		t.Fatal(err)
+	}
+}